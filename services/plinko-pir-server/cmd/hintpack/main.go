@@ -0,0 +1,83 @@
+// Command hintpack converts a persisted client.Client between its compact
+// binary form (client.MarshalBinary) and a JSON dump (client.Snapshot),
+// for inspecting or hand-editing offline state without writing Go code.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"plinko-pir-server/pkg/client"
+)
+
+func main() {
+	mode := flag.String("mode", "", "to-json or to-binary")
+	inputPath := flag.String("in", "", "Input file path")
+	outputPath := flag.String("out", "", "Output file path")
+	flag.Parse()
+
+	if *inputPath == "" || *outputPath == "" {
+		fmt.Println("-in and -out are required")
+		os.Exit(1)
+	}
+
+	switch *mode {
+	case "to-json":
+		toJSON(*inputPath, *outputPath)
+	case "to-binary":
+		toBinary(*inputPath, *outputPath)
+	default:
+		fmt.Println("-mode must be to-json or to-binary")
+		os.Exit(1)
+	}
+}
+
+func toJSON(inputPath, outputPath string) {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		fmt.Printf("failed to read %s: %v\n", inputPath, err)
+		os.Exit(1)
+	}
+
+	var c client.Client
+	if err := c.UnmarshalBinary(data); err != nil {
+		fmt.Printf("failed to decode binary state: %v\n", err)
+		os.Exit(1)
+	}
+
+	out, err := json.MarshalIndent(c.Snapshot(), "", "  ")
+	if err != nil {
+		fmt.Printf("failed to marshal JSON: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(outputPath, out, 0o644); err != nil {
+		fmt.Printf("failed to write %s: %v\n", outputPath, err)
+		os.Exit(1)
+	}
+}
+
+func toBinary(inputPath, outputPath string) {
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		fmt.Printf("failed to read %s: %v\n", inputPath, err)
+		os.Exit(1)
+	}
+
+	var snapshot client.Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		fmt.Printf("failed to parse JSON: %v\n", err)
+		os.Exit(1)
+	}
+
+	out, err := client.FromSnapshot(snapshot).MarshalBinary()
+	if err != nil {
+		fmt.Printf("failed to encode binary state: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(outputPath, out, 0o644); err != nil {
+		fmt.Printf("failed to write %s: %v\n", outputPath, err)
+		os.Exit(1)
+	}
+}
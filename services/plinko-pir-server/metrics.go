@@ -0,0 +1,109 @@
+package main
+
+import (
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// pSizeBucket buckets |P| into coarse powers-of-ten labels so the
+// plinko_query_duration_seconds histogram shows how tail latency scales
+// with the size of the client's partition without a high-cardinality label.
+func pSizeBucket(pSize int) string {
+	switch {
+	case pSize <= 0:
+		return "0"
+	case pSize <= 10:
+		return "1-10"
+	case pSize <= 100:
+		return "11-100"
+	case pSize <= 1000:
+		return "101-1000"
+	case pSize <= 10000:
+		return "1001-10000"
+	default:
+		return "10000+"
+	}
+}
+
+var (
+	plinkoQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "plinko_query_duration_seconds",
+		Help:    "Latency of HandlePlinkoQuery, labeled by |P| bucket.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"p_size"})
+
+	plaintextQueryDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "plaintext_query_duration_seconds",
+		Help:    "Latency of the plaintext (non-private) query handler.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	plinkoQueriesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "plinko_queries_total",
+		Help: "Total number of Plinko PIR queries handled.",
+	})
+
+	plinkoInvalidOffsetsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "plinko_invalid_offsets_total",
+		Help: "Total number of out-of-range block offsets rejected by HandlePlinkoQuery.",
+	})
+
+	csrBatchQueriesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "plinko_csr_batch_queries_total",
+		Help: "Total number of sub-queries answered via POST /plinko/batch/csr.",
+	})
+
+	csrBatchQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "plinko_csr_batch_query_duration_seconds",
+		Help:    "Latency of csrBatchHandler, labeled by sub-query count bucket.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"p_size"})
+
+	dbSizeGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "plinko_db_size",
+		Help: "Number of entries in the database.",
+	})
+
+	chunkSizeGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "plinko_chunk_size",
+		Help: "Configured Plinko chunk size.",
+	})
+
+	setSizeGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "plinko_set_size",
+		Help: "Configured Plinko set size.",
+	})
+
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "plinko_heap_alloc_bytes",
+		Help: "Resident heap bytes, as reported by runtime.MemStats.",
+	}, func() float64 {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return float64(m.HeapAlloc)
+	})
+)
+
+// recordServerParams publishes the server's fixed parameters as gauges once
+// it's finished loading its database.
+func (s *PlinkoPIRServer) recordServerParams() {
+	dbSizeGauge.Set(float64(s.dbSize))
+	chunkSizeGauge.Set(float64(s.chunkSize))
+	setSizeGauge.Set(float64(s.setSize))
+}
+
+// observePlinkoQuery records elapsedSeconds under the p_size bucket for pSize.
+func observePlinkoQuery(pSize int, elapsedSeconds float64) {
+	plinkoQueriesTotal.Inc()
+	plinkoQueryDuration.WithLabelValues(pSizeBucket(pSize)).Observe(elapsedSeconds)
+}
+
+// observeCSRBatchQuery records elapsedSeconds, for a /plinko/batch/csr
+// request answering numQueries sub-queries, under the same bucketing
+// observePlinkoQuery uses.
+func observeCSRBatchQuery(numQueries int, elapsedSeconds float64) {
+	csrBatchQueriesTotal.Add(float64(numQueries))
+	csrBatchQueryDuration.WithLabelValues(pSizeBucket(numQueries)).Observe(elapsedSeconds)
+}
@@ -0,0 +1,104 @@
+// Package batchserver answers client.BatchRequest-shaped batched online
+// queries against a storage.DBBackend. It mirrors client.BatchRequest's
+// CSR layout with its own Request type rather than importing pkg/client,
+// since pkg/client already depends on pkg/storage.
+package batchserver
+
+import (
+	"runtime"
+	"sync"
+
+	"plinko-pir-server/pkg/storage"
+)
+
+// Request is the server-side view of a client.BatchRequest: Indices is the
+// deduplicated set of database indices the batch touches, and
+// Members[Offsets[i]:Offsets[i+1]] lists the positions in Indices making
+// up sub-query i's punctured set.
+type Request struct {
+	Indices []uint64
+	Members []uint32
+	Offsets []uint32
+}
+
+// ComputeParities reads every index in req.Indices from b exactly once
+// (so a shared index costs one backend read no matter how many
+// sub-queries need it), then fans the CSR groups across GOMAXPROCS
+// workers, each XOR-reducing its slice of sub-queries into a preallocated
+// output slice. The result is one parity per sub-query, in req.Offsets
+// order.
+func ComputeParities(b storage.DBBackend, req Request) ([][4]uint64, error) {
+	values := make([][4]uint64, len(req.Indices))
+	for i, idx := range req.Indices {
+		v, err := b.Get(idx)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	numQueries := len(req.Offsets) - 1
+	if numQueries < 0 {
+		numQueries = 0
+	}
+	parities := make([][4]uint64, numQueries)
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > numQueries {
+		workers = numQueries
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	shardSize := (numQueries + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start := w * shardSize
+		end := start + shardSize
+		if end > numQueries {
+			end = numQueries
+		}
+		if start >= end {
+			continue
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for q := start; q < end; q++ {
+				var parity [4]uint64
+				for _, pos := range req.Members[req.Offsets[q]:req.Offsets[q+1]] {
+					v := values[pos]
+					for k := 0; k < 4; k++ {
+						parity[k] ^= v[k]
+					}
+				}
+				parities[q] = parity
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	return parities, nil
+}
+
+// ComputeParity reads each of indices from b and XORs them into a single
+// parity. It answers a client.RefreshRequest, whose Indices are already a
+// single sub-query's set rather than a CSR-packed batch, so routing it
+// through ComputeParities would mean building a degenerate one-group
+// Request just to unwrap its only result.
+func ComputeParity(b storage.DBBackend, indices []uint64) ([4]uint64, error) {
+	var parity [4]uint64
+	for _, idx := range indices {
+		v, err := b.Get(idx)
+		if err != nil {
+			return [4]uint64{}, err
+		}
+		for k := 0; k < 4; k++ {
+			parity[k] ^= v[k]
+		}
+	}
+	return parity, nil
+}
@@ -0,0 +1,114 @@
+package batchserver
+
+import (
+	"errors"
+	"testing"
+
+	"plinko-pir-server/pkg/storage"
+)
+
+func testDB(n int) storage.DBBackend {
+	entries := make([][4]uint64, n)
+	for i := range entries {
+		entries[i] = [4]uint64{uint64(i), 0, 0, 0}
+	}
+	return storage.NewMemoryBackend(entries)
+}
+
+func TestComputeParitiesDedupsSharedIndices(t *testing.T) {
+	db := testDB(10)
+
+	// Two sub-queries sharing index 3: sub-query 0 is {1, 3}, sub-query 1
+	// is {3, 5}. Indices lists the shared index once.
+	req := Request{
+		Indices: []uint64{1, 3, 5},
+		Members: []uint32{0, 1, 1, 2},
+		Offsets: []uint32{0, 2, 4},
+	}
+
+	parities, err := ComputeParities(db, req)
+	if err != nil {
+		t.Fatalf("ComputeParities: %v", err)
+	}
+	if len(parities) != 2 {
+		t.Fatalf("got %d parities, want 2", len(parities))
+	}
+
+	want0 := [4]uint64{1 ^ 3, 0, 0, 0}
+	want1 := [4]uint64{3 ^ 5, 0, 0, 0}
+	if parities[0] != want0 {
+		t.Errorf("parities[0] = %v, want %v", parities[0], want0)
+	}
+	if parities[1] != want1 {
+		t.Errorf("parities[1] = %v, want %v", parities[1], want1)
+	}
+}
+
+func TestComputeParitiesEmptySubQuery(t *testing.T) {
+	db := testDB(5)
+
+	req := Request{
+		Indices: []uint64{0, 1},
+		Members: []uint32{0},
+		Offsets: []uint32{0, 1, 1},
+	}
+
+	parities, err := ComputeParities(db, req)
+	if err != nil {
+		t.Fatalf("ComputeParities: %v", err)
+	}
+	if len(parities) != 2 {
+		t.Fatalf("got %d parities, want 2", len(parities))
+	}
+	if parities[1] != ([4]uint64{}) {
+		t.Errorf("parities[1] = %v, want zero parity for an empty member set", parities[1])
+	}
+}
+
+func TestComputeParitiesNoSubQueries(t *testing.T) {
+	db := testDB(5)
+
+	parities, err := ComputeParities(db, Request{Offsets: []uint32{0}})
+	if err != nil {
+		t.Fatalf("ComputeParities: %v", err)
+	}
+	if len(parities) != 0 {
+		t.Errorf("got %d parities, want 0", len(parities))
+	}
+}
+
+func TestComputeParitiesPropagatesBackendError(t *testing.T) {
+	db := testDB(5)
+
+	req := Request{
+		Indices: []uint64{0, 100},
+		Members: []uint32{0, 1},
+		Offsets: []uint32{0, 2},
+	}
+
+	if _, err := ComputeParities(db, req); !errors.Is(err, storage.ErrOutOfRange) {
+		t.Errorf("ComputeParities err = %v, want %v", err, storage.ErrOutOfRange)
+	}
+}
+
+func TestComputeParityMatchesComputeParities(t *testing.T) {
+	db := testDB(8)
+
+	indices := []uint64{2, 4, 6}
+	single, err := ComputeParity(db, indices)
+	if err != nil {
+		t.Fatalf("ComputeParity: %v", err)
+	}
+
+	batch, err := ComputeParities(db, Request{
+		Indices: indices,
+		Members: []uint32{0, 1, 2},
+		Offsets: []uint32{0, 3},
+	})
+	if err != nil {
+		t.Fatalf("ComputeParities: %v", err)
+	}
+	if len(batch) != 1 || batch[0] != single {
+		t.Errorf("ComputeParities = %v, want [%v] to match ComputeParity", batch, single)
+	}
+}
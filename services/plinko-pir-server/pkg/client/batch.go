@@ -0,0 +1,150 @@
+package client
+
+import "sort"
+
+// HintHandle identifies the hint a sub-query in a batch consumed, so the
+// corresponding entry in the server's parity response can be turned back
+// into a value via Reconstruct.
+type HintHandle struct {
+	Target uint64
+	Hint   Hint
+}
+
+// BatchRequest packs the punctured index sets for many online queries
+// into a single round trip. Indices is the deduplicated set of database
+// indices touched by any sub-query in the batch: a shared index (common
+// between a backup hint and a primary partition, or between two backup
+// hints) appears exactly once, so the server's worker (see the adjacent
+// server package) XORs it into a running parity only once rather than
+// once per sub-query that needs it. Offsets is a CSR-style prefix array
+// into Members, where Members[Offsets[i]:Offsets[i+1]] lists the
+// positions in Indices making up sub-query i's punctured set. Sub-queries
+// are laid out primary-hint first, then backup-hint, keeping target order
+// within each kind; this is the order of the HintHandles QueryBatch
+// returns, not necessarily the order of the targets passed to it.
+type BatchRequest struct {
+	Indices []uint64
+	Members []uint32
+	Offsets []uint32
+}
+
+// batchConfig holds QueryBatch's optional settings.
+type batchConfig struct {
+	maxBatchIndices int
+}
+
+// BatchOption configures QueryBatch.
+type BatchOption func(*batchConfig)
+
+// WithMaxBatchIndices bounds the number of deduplicated indices a
+// BatchRequest may contain, so embedders can cap per-request bandwidth.
+// QueryBatch fails (returns ok=false) rather than silently truncating the
+// batch when the limit would be exceeded; callers should split targets
+// into smaller batches themselves.
+func WithMaxBatchIndices(n int) BatchOption {
+	return func(cfg *batchConfig) {
+		cfg.maxBatchIndices = n
+	}
+}
+
+// queryKind distinguishes the two hint pools QueryBatch draws from, so
+// sub-queries can be grouped by kind before laying out the CSR request:
+// the server evaluates one contiguous kind at a time, improving hint
+// cache locality when it computes parities.
+type queryKind int
+
+const (
+	kindPrimary queryKind = iota
+	kindBackup
+)
+
+type subQuery struct {
+	target  uint64
+	hint    *Hint
+	kind    queryKind
+	request []uint64
+}
+
+// QueryBatch packs many online queries into a single BatchRequest. It
+// deduplicates indices across sub-queries and orders them by hint kind
+// (primary hints before backup hints) for server-side cache locality. It
+// returns false, without consuming any hint, if a target has no unused
+// hint covering it or the batch would exceed WithMaxBatchIndices.
+func (c *Client) QueryBatch(targets []uint64, opts ...BatchOption) (BatchRequest, []HintHandle, bool) {
+	var cfg batchConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	// hint.Used is set as each target is resolved, in this same loop,
+	// not afterward: two targets in the batch that fall in the same
+	// iPRF bin must not be handed the same *Hint, the way two separate
+	// Query calls never would be. Marking inline makes the second
+	// target's findUnusedHint see the bin as consumed and correctly
+	// fall through to a backup hint (or fail the batch) instead of
+	// silently reusing one. consumed tracks what's been marked so a
+	// later failure (an uncovered target, or the size limit below) can
+	// roll the marks back -- QueryBatch must not consume any hint
+	// unless the whole batch succeeds.
+	consumed := make([]*Hint, 0, len(targets))
+	rollback := func() {
+		for _, hint := range consumed {
+			hint.Used = false
+		}
+	}
+
+	subs := make([]subQuery, len(targets))
+	for i, target := range targets {
+		hint, kind, ok := c.findUnusedHint(target)
+		if !ok {
+			rollback()
+			return BatchRequest{}, nil, false
+		}
+		hint.Used = true
+		consumed = append(consumed, hint)
+		subs[i] = subQuery{
+			target:  target,
+			hint:    hint,
+			kind:    kind,
+			request: puncture(c.indicesFor(*hint, kind, target), target),
+		}
+	}
+
+	sort.SliceStable(subs, func(i, j int) bool { return subs[i].kind < subs[j].kind })
+
+	req := BatchRequest{Offsets: make([]uint32, len(subs)+1)}
+	positions := make(map[uint64]uint32)
+	handles := make([]HintHandle, len(subs))
+
+	for i, sub := range subs {
+		for _, idx := range sub.request {
+			pos, seen := positions[idx]
+			if !seen {
+				pos = uint32(len(req.Indices))
+				req.Indices = append(req.Indices, idx)
+				positions[idx] = pos
+			}
+			req.Members = append(req.Members, pos)
+		}
+		req.Offsets[i+1] = uint32(len(req.Members))
+		handles[i] = HintHandle{Target: sub.target, Hint: *sub.hint}
+	}
+
+	if cfg.maxBatchIndices > 0 && len(req.Indices) > cfg.maxBatchIndices {
+		rollback()
+		return BatchRequest{}, nil, false
+	}
+
+	return req, handles, true
+}
+
+// ReconstructBatch recovers the values for a batch of queries, given the
+// server's per-sub-query parities (one per HintHandle, same order as
+// returned by QueryBatch) and the handles themselves.
+func (c *Client) ReconstructBatch(parities [][4]uint64, handles []HintHandle) [][4]uint64 {
+	values := make([][4]uint64, len(handles))
+	for i, handle := range handles {
+		values[i] = c.Reconstruct(parities[i], handle.Hint)
+	}
+	return values
+}
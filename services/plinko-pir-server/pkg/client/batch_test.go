@@ -0,0 +1,177 @@
+package client
+
+import "testing"
+
+func TestQueryBatchEndToEnd(t *testing.T) {
+	n := uint64(1000)
+	db := make([][4]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		db[i][0] = i * 100
+	}
+
+	m := uint64(50)
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+	keyBeta[0] = 1
+
+	c := NewClient(n, m, keyAlpha, keyBeta)
+	c.randSource = newDeterministicRandSource()
+
+	iter := 0
+	dbStream := func() (DBEntry, bool) {
+		if iter >= int(n) {
+			return DBEntry{}, false
+		}
+		entry := DBEntry{Index: uint64(iter), Value: db[iter]}
+		iter++
+		return entry, true
+	}
+	c.HintInit(dbStream)
+
+	targets := []uint64{42, 99, 7}
+	req, handles, ok := c.QueryBatch(targets)
+	if !ok {
+		t.Fatalf("QueryBatch failed: no hint found for one of %v", targets)
+	}
+	if len(handles) != len(targets) {
+		t.Fatalf("got %d handles, want %d", len(handles), len(targets))
+	}
+	if len(req.Offsets) != len(targets)+1 {
+		t.Fatalf("got %d offsets, want %d", len(req.Offsets), len(targets)+1)
+	}
+
+	// Simulate the server: one parity per sub-query, reading from the
+	// deduplicated Indices via the Members/Offsets CSR groups.
+	parities := make([][4]uint64, len(targets))
+	for q := 0; q < len(targets); q++ {
+		var parity [4]uint64
+		for _, pos := range req.Members[req.Offsets[q]:req.Offsets[q+1]] {
+			idx := req.Indices[pos]
+			parity[0] ^= db[idx][0]
+		}
+		parities[q] = parity
+	}
+
+	values := c.ReconstructBatch(parities, handles)
+	for i, handle := range handles {
+		want := db[handle.Target][0]
+		if values[i][0] != want {
+			t.Errorf("target %d: got %d, want %d", handle.Target, values[i][0], want)
+		}
+	}
+}
+
+// TestQueryBatchSameBinFallsBackToBackup covers two targets landing in the
+// same iPRF bin within a single QueryBatch call: the second must not reuse
+// the first's hint (that would doubly consume one hint, breaking the
+// single-use-hint invariant), it must fall back to a backup hint the same
+// way two separate Query calls would.
+func TestQueryBatchSameBinFallsBackToBackup(t *testing.T) {
+	n := uint64(1000)
+	db := make([][4]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		db[i][0] = i * 100
+	}
+
+	m := uint64(50)
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+	keyBeta[0] = 1
+
+	c := NewClient(n, m, keyAlpha, keyBeta)
+	c.randSource = newDeterministicRandSource()
+
+	iter := 0
+	dbStream := func() (DBEntry, bool) {
+		if iter >= int(n) {
+			return DBEntry{}, false
+		}
+		entry := DBEntry{Index: uint64(iter), Value: db[iter]}
+		iter++
+		return entry, true
+	}
+	c.OfflineSetup(dbStream, 10, 20)
+
+	var a, b uint64
+	found := false
+	for i := uint64(0); i < n && !found; i++ {
+		for j := i + 1; j < n; j++ {
+			if c.iprf.F(i) == c.iprf.F(j) {
+				a, b, found = i, j, true
+				break
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("no two indices in [0, %d) share an iPRF bin", n)
+	}
+
+	req, handles, ok := c.QueryBatch([]uint64{a, b})
+	if !ok {
+		t.Fatalf("QueryBatch failed for same-bin targets %d, %d", a, b)
+	}
+	// Under the default ExplicitIndices mode, only backup hints carry
+	// their member set in Indices; a primary hint's is empty (see
+	// indicesFor). a's primary hint must answer first, so b -- sharing
+	// its bin -- must have fallen back to a backup hint rather than
+	// reusing a's already-consumed primary hint.
+	if len(handles[0].Hint.Indices) != 0 {
+		t.Fatalf("target %d unexpectedly resolved to a backup hint", a)
+	}
+	if len(handles[1].Hint.Indices) == 0 {
+		t.Errorf("target %d (same bin as %d) reused the primary hint instead of falling back to a backup hint", b, a)
+	}
+
+	parities := make([][4]uint64, len(handles))
+	for q := range handles {
+		var parity [4]uint64
+		for _, pos := range req.Members[req.Offsets[q]:req.Offsets[q+1]] {
+			idx := req.Indices[pos]
+			parity[0] ^= db[idx][0]
+		}
+		parities[q] = parity
+	}
+
+	values := c.ReconstructBatch(parities, handles)
+	for i, handle := range handles {
+		want := db[handle.Target][0]
+		if values[i][0] != want {
+			t.Errorf("target %d: got %d, want %d", handle.Target, values[i][0], want)
+		}
+	}
+}
+
+func TestQueryBatchMaxIndices(t *testing.T) {
+	n := uint64(1000)
+	db := make([][4]uint64, n)
+
+	m := uint64(50)
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+
+	c := NewClient(n, m, keyAlpha, keyBeta)
+	c.randSource = newDeterministicRandSource()
+
+	iter := 0
+	dbStream := func() (DBEntry, bool) {
+		if iter >= int(n) {
+			return DBEntry{}, false
+		}
+		entry := DBEntry{Index: uint64(iter), Value: db[iter]}
+		iter++
+		return entry, true
+	}
+	c.HintInit(dbStream)
+
+	_, _, ok := c.QueryBatch([]uint64{1, 2, 3}, WithMaxBatchIndices(1))
+	if ok {
+		t.Fatalf("QueryBatch should have failed: batch needs more than 1 index")
+	}
+
+	// A rejected batch must not leave any hint marked used.
+	for i, h := range c.hints {
+		if h.Used {
+			t.Errorf("hint %d marked used after a rejected batch", i)
+		}
+	}
+}
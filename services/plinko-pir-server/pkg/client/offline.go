@@ -20,6 +20,23 @@ type Hint struct {
 	Indices []uint64
 }
 
+// HintMode selects how a Client represents its backup hints.
+type HintMode int
+
+const (
+	// ExplicitIndices stores each backup hint's member indices directly
+	// in Hint.Indices. This is the default, so existing callers of
+	// InitBackupHints/OfflineSetup are unaffected.
+	ExplicitIndices HintMode = iota
+	// StructuredPRP defines backup hint i implicitly as
+	// { backupPRP.Inverse(j) : j in [i*setSize, (i+1)*setSize) } under a
+	// PRP keyed separately from the iPRF's. Indices is left empty;
+	// membership and the hint's index set are both derived on demand via
+	// backupPRP, so InitBackupHints/OfflineSetup run in O(1) per entry
+	// instead of O(backupCount*backupSetSize).
+	StructuredPRP
+)
+
 // Client represents a Plinko PIR client
 type Client struct {
 	iprf        *iprf.IPRF
@@ -34,6 +51,30 @@ type Client struct {
 	keyBeta  []byte // Key for PMNS (part of iPRF)
 
 	randSource func(max uint64) uint64
+
+	hintMode         HintMode
+	backupPRP        *iprf.PRP // only set when hintMode == StructuredPRP
+	backupSetSize    int       // only set when hintMode == StructuredPRP
+	nextBackupBucket uint64    // only used when hintMode == StructuredPRP; see RequestBackupRefresh
+}
+
+// SetHintMode selects how backup hints are represented. Call it before
+// InitBackupHints/OfflineSetup populate backupHints; it has no effect on
+// already-populated hints.
+func (c *Client) SetHintMode(mode HintMode) {
+	c.hintMode = mode
+}
+
+// backupPRPKey derives the PRP key StructuredPRP backup hints use for
+// bucket assignment from keyAlpha, tweaked so it differs from the key the
+// iPRF itself uses for its PRP.
+func backupPRPKey(keyAlpha []byte) []byte {
+	key := make([]byte, len(keyAlpha))
+	copy(key, keyAlpha)
+	for i := range key {
+		key[i] ^= 0xba
+	}
+	return key
 }
 
 // NewClient creates a new client
@@ -78,51 +119,175 @@ type DBEntry struct {
 // HintInit performs the offline phase
 // It iterates over the entire database and updates hints.
 // dbStream is a function that yields database entries.
+//
+// HintInit is a thin wrapper around OfflineSetup with backupCount 0; for
+// streaming or one-shot DBs where replaying dbStream is expensive or
+// impossible, call OfflineSetup directly so regular and backup hints are
+// both populated in the single pass it affords.
 func (c *Client) HintInit(dbStream func() (DBEntry, bool)) {
-	// Initialize hints to 0
+	c.OfflineSetup(dbStream, 0, 0)
+}
+
+// InitBackupHints generates backup hints
+// count: number of backup hints to generate
+// setSize: size of each backup hint set
+// dbStream: needs to stream DB again (or we do it in one pass if possible)
+//
+// InitBackupHints only populates backup hints, re-streaming dbStream for
+// them with the same backup-hint construction OfflineSetup uses (it does
+// not call OfflineSetup, so regular hints are left untouched); prefer
+// OfflineSetup when regular hints haven't been built yet, since it builds
+// both in one pass.
+func (c *Client) InitBackupHints(count int, setSize int, dbStream func() (DBEntry, bool)) {
+	if c.hintMode == StructuredPRP {
+		c.initBackupHintsStructured(count, setSize, dbStream)
+		return
+	}
+
+	c.backupHints = newBackupHints(count, setSize, c.n, c.randSource)
+
+	reverse := backupReverseIndex(c.backupHints)
+	for {
+		entry, ok := dbStream()
+		if !ok {
+			break
+		}
+		for _, hintIdx := range reverse[entry.Index] {
+			for k := 0; k < 4; k++ {
+				c.backupHints[hintIdx].Parity[k] ^= entry.Value[k]
+			}
+		}
+	}
+}
+
+// maxStructuredBuckets returns the number of buckets backupPRP's domain
+// [0, n) actually has room for at setSize members each -- the last bucket
+// is partial (and has no members at all once its start reaches n), so a
+// backupCount beyond this would only produce buckets structuredBucketIndices
+// can't fill in. setSize 0 has no buckets at all.
+func maxStructuredBuckets(n uint64, setSize int) int {
+	if setSize <= 0 {
+		return 0
+	}
+	return int((n + uint64(setSize) - 1) / uint64(setSize))
+}
+
+// initBackupHintsStructured is InitBackupHints under StructuredPRP: each
+// backup hint's membership is a bucket of backupPRP's range, so a
+// streamed entry updates at most one backup hint's parity via a single
+// Permute call, rather than a reverse-index lookup.
+func (c *Client) initBackupHintsStructured(count int, setSize int, dbStream func() (DBEntry, bool)) {
+	if max := maxStructuredBuckets(c.n, setSize); count > max {
+		count = max
+	}
+
+	c.backupPRP = iprf.NewPRP(backupPRPKey(c.keyAlpha), c.n)
+	c.backupSetSize = setSize
+	c.backupHints = make([]Hint, count)
+	c.nextBackupBucket = uint64(count)
+
+	if setSize == 0 {
+		for {
+			if _, ok := dbStream(); !ok {
+				break
+			}
+		}
+		return
+	}
+
+	for {
+		entry, ok := dbStream()
+		if !ok {
+			break
+		}
+		if bucket := c.backupPRP.Permute(entry.Index) / uint64(setSize); bucket < uint64(count) {
+			for k := 0; k < 4; k++ {
+				c.backupHints[bucket].Parity[k] ^= entry.Value[k]
+			}
+		}
+	}
+}
+
+// OfflineSetup is the offline phase: a single pass over dbStream that
+// populates both the regular hints (indexed by the iPRF's forward
+// mapping) and backupCount backup hints of backupSetSize random indices
+// each. It precomputes the backup hint index sets up front and a reverse
+// index (database index -> backup hints containing it) so each streamed
+// entry updates its backup hints in O(hints referencing it) rather than
+// the O(backupCount*backupSetSize) of scanning every hint's index set.
+// Under StructuredPRP the backup hints are backupPRP buckets instead (see
+// HintMode), and backupCount is capped at maxStructuredBuckets. Pass
+// backupCount 0 to skip backup hints entirely.
+func (c *Client) OfflineSetup(dbStream func() (DBEntry, bool), backupCount, backupSetSize int) {
 	for i := range c.hints {
 		c.hints[i].Parity = [4]uint64{0, 0, 0, 0}
 		c.hints[i].Used = false
 	}
 
-	// Stream database
+	if c.hintMode == StructuredPRP {
+		if max := maxStructuredBuckets(c.n, backupSetSize); backupCount > max {
+			backupCount = max
+		}
+
+		c.backupPRP = iprf.NewPRP(backupPRPKey(c.keyAlpha), c.n)
+		c.backupSetSize = backupSetSize
+		c.backupHints = make([]Hint, backupCount)
+		c.nextBackupBucket = uint64(backupCount)
+
+		for {
+			entry, ok := dbStream()
+			if !ok {
+				break
+			}
+			if y := c.iprf.F(entry.Index); y < uint64(len(c.hints)) {
+				for k := 0; k < 4; k++ {
+					c.hints[y].Parity[k] ^= entry.Value[k]
+				}
+			}
+			if backupSetSize > 0 {
+				if bucket := c.backupPRP.Permute(entry.Index) / uint64(backupSetSize); bucket < uint64(backupCount) {
+					for k := 0; k < 4; k++ {
+						c.backupHints[bucket].Parity[k] ^= entry.Value[k]
+					}
+				}
+			}
+		}
+		return
+	}
+
+	c.backupHints = newBackupHints(backupCount, backupSetSize, c.n, c.randSource)
+	reverse := backupReverseIndex(c.backupHints)
+
 	for {
 		entry, ok := dbStream()
 		if !ok {
 			break
 		}
 
-		// For each entry x, find which hints it belongs to using iPRF forward
-		// F(k, x) -> y
-		y := c.iprf.F(entry.Index)
-
-		if y < uint64(len(c.hints)) {
-			// XOR 256-bit value
+		if y := c.iprf.F(entry.Index); y < uint64(len(c.hints)) {
 			for k := 0; k < 4; k++ {
 				c.hints[y].Parity[k] ^= entry.Value[k]
 			}
 		}
+
+		for _, hintIdx := range reverse[entry.Index] {
+			for k := 0; k < 4; k++ {
+				c.backupHints[hintIdx].Parity[k] ^= entry.Value[k]
+			}
+		}
 	}
 }
 
-// InitBackupHints generates backup hints
-// count: number of backup hints to generate
-// setSize: size of each backup hint set
-// dbStream: needs to stream DB again (or we do it in one pass if possible)
-// For simplicity, we assume we can stream again or this is called during HintInit if we merge logic.
-// Here we implement it as a separate pass for clarity.
-func (c *Client) InitBackupHints(count int, setSize int, dbStream func() (DBEntry, bool)) {
-	c.backupHints = make([]Hint, count)
-
-	// Generate random sets for backup hints
-	// We use a fixed seed for reproducibility in this PoC
+// newBackupHints pre-generates count backup hints, each a random set of
+// setSize distinct indices in [0, n), with zeroed parities.
+func newBackupHints(count, setSize int, n uint64, randSource func(max uint64) uint64) []Hint {
+	hints := make([]Hint, count)
 	for i := 0; i < count; i++ {
-		// Generate a random set of indices
 		indices := make([]uint64, setSize)
 		seen := make(map[uint64]bool)
 		for j := 0; j < setSize; j++ {
 			for {
-				idx := c.randSource(c.n)
+				idx := randSource(n)
 				if !seen[idx] {
 					seen[idx] = true
 					indices[j] = idx
@@ -130,30 +295,25 @@ func (c *Client) InitBackupHints(count int, setSize int, dbStream func() (DBEntr
 				}
 			}
 		}
-		c.backupHints[i] = Hint{
+		hints[i] = Hint{
 			Indices: indices,
 			Parity:  [4]uint64{0, 0, 0, 0},
 			Used:    false,
 		}
 	}
+	return hints
+}
 
-	// Compute parities
-	for {
-		entry, ok := dbStream()
-		if !ok {
-			break
-		}
-
-		// Check which backup hints contain this entry
-		for i := range c.backupHints {
-			for _, idx := range c.backupHints[i].Indices {
-				if idx == entry.Index {
-					for k := 0; k < 4; k++ {
-						c.backupHints[i].Parity[k] ^= entry.Value[k]
-					}
-					break
-				}
-			}
+// backupReverseIndex maps each database index to the list of backup hints
+// (by position in hints) whose set contains it, so OfflineSetup and
+// InitBackupHints can update the right backup hints in O(1) per streamed
+// entry instead of scanning every hint's index set.
+func backupReverseIndex(hints []Hint) map[uint64][]int {
+	reverse := make(map[uint64][]int)
+	for i, hint := range hints {
+		for _, idx := range hint.Indices {
+			reverse[idx] = append(reverse[idx], i)
 		}
 	}
+	return reverse
 }
@@ -0,0 +1,58 @@
+package client
+
+import "testing"
+
+// TestOfflineSetupMatchesSeparatePasses checks that OfflineSetup's single
+// pass produces the same hints as calling HintInit and InitBackupHints
+// against independent streams of the same database.
+func TestOfflineSetupMatchesSeparatePasses(t *testing.T) {
+	n := uint64(200)
+	db := make([][4]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		db[i][0] = uint64(i) * 7
+	}
+
+	m := uint64(20)
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+	keyBeta[0] = 1
+
+	getStream := func() func() (DBEntry, bool) {
+		iter := 0
+		return func() (DBEntry, bool) {
+			if iter >= int(n) {
+				return DBEntry{}, false
+			}
+			entry := DBEntry{Index: uint64(iter), Value: db[iter]}
+			iter++
+			return entry, true
+		}
+	}
+
+	separate := NewClient(n, m, keyAlpha, keyBeta)
+	separate.randSource = newDeterministicRandSource()
+	separate.HintInit(getStream())
+	separate.InitBackupHints(15, 8, getStream())
+
+	combined := NewClient(n, m, keyAlpha, keyBeta)
+	combined.randSource = newDeterministicRandSource()
+	combined.OfflineSetup(getStream(), 15, 8)
+
+	if len(combined.hints) != len(separate.hints) {
+		t.Fatalf("got %d regular hints, want %d", len(combined.hints), len(separate.hints))
+	}
+	for i := range separate.hints {
+		if combined.hints[i].Parity != separate.hints[i].Parity {
+			t.Errorf("regular hint %d parity mismatch: got %v, want %v", i, combined.hints[i].Parity, separate.hints[i].Parity)
+		}
+	}
+
+	if len(combined.backupHints) != len(separate.backupHints) {
+		t.Fatalf("got %d backup hints, want %d", len(combined.backupHints), len(separate.backupHints))
+	}
+	for i := range separate.backupHints {
+		if combined.backupHints[i].Parity != separate.backupHints[i].Parity {
+			t.Errorf("backup hint %d parity mismatch: got %v, want %v", i, combined.backupHints[i].Parity, separate.backupHints[i].Parity)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package client
+
+import "sync"
+
+// HintInitParallel is HintInit fanned out across workers goroutines. A
+// single reader goroutine drains dbStream (dbStream itself is an
+// arbitrary closure, not assumed to be concurrency-safe) and distributes
+// entries over a channel; each worker accumulates its own []Hint shard of
+// size m, XOR-ing entries into it independently, and the shards are
+// XOR-merged into c.hints once every worker finishes. XOR is associative
+// and commutative, so the merge order doesn't matter.
+//
+// This relies on iprf.IPRF.F and the underlying iprf.PRP being safe for
+// concurrent use: PRP's Feistel parameters are precomputed once in
+// NewPRP and never mutated afterwards, and cipher.Block.Encrypt (used by
+// PRP's round function) is already goroutine-safe with no shared scratch
+// buffers.
+func (c *Client) HintInitParallel(dbStream func() (DBEntry, bool), workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	entries := make(chan DBEntry, workers*4)
+	go func() {
+		defer close(entries)
+		for {
+			entry, ok := dbStream()
+			if !ok {
+				return
+			}
+			entries <- entry
+		}
+	}()
+
+	shards := make([][]Hint, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		shards[w] = make([]Hint, len(c.hints))
+		wg.Add(1)
+		go func(shard []Hint) {
+			defer wg.Done()
+			for entry := range entries {
+				if y := c.iprf.F(entry.Index); y < uint64(len(shard)) {
+					for k := 0; k < 4; k++ {
+						shard[y].Parity[k] ^= entry.Value[k]
+					}
+				}
+			}
+		}(shards[w])
+	}
+	wg.Wait()
+
+	for i := range c.hints {
+		c.hints[i].Parity = [4]uint64{0, 0, 0, 0}
+		c.hints[i].Used = false
+		for _, shard := range shards {
+			for k := 0; k < 4; k++ {
+				c.hints[i].Parity[k] ^= shard[i].Parity[k]
+			}
+		}
+	}
+}
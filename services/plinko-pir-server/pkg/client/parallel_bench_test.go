@@ -0,0 +1,49 @@
+package client
+
+import (
+	"runtime"
+	"testing"
+)
+
+// benchStream returns a fresh dbStream closure over a deterministic,
+// not-actually-allocated database: entry values are derived from the
+// index so BenchmarkHintInit{Serial,Parallel} don't need an n=2^24
+// [4]uint64 slice (512 MiB) in memory just to measure the hinting loop.
+func benchStream(n uint64) func() (DBEntry, bool) {
+	iter := uint64(0)
+	return func() (DBEntry, bool) {
+		if iter >= n {
+			return DBEntry{}, false
+		}
+		entry := DBEntry{Index: iter, Value: [4]uint64{iter, 0, 0, 0}}
+		iter++
+		return entry, true
+	}
+}
+
+const benchN = uint64(1) << 24
+
+func BenchmarkHintInitSerial(b *testing.B) {
+	m := benchN / 64
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := NewClient(benchN, m, keyAlpha, keyBeta)
+		c.HintInit(benchStream(benchN))
+	}
+}
+
+func BenchmarkHintInitParallel(b *testing.B) {
+	m := benchN / 64
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+	workers := runtime.GOMAXPROCS(0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := NewClient(benchN, m, keyAlpha, keyBeta)
+		c.HintInitParallel(benchStream(benchN), workers)
+	}
+}
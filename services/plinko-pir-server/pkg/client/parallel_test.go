@@ -0,0 +1,42 @@
+package client
+
+import "testing"
+
+func TestHintInitParallelMatchesSerial(t *testing.T) {
+	n := uint64(500)
+	db := make([][4]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		db[i][0] = uint64(i) * 3
+	}
+
+	m := uint64(40)
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+	keyBeta[0] = 1
+
+	getStream := func() func() (DBEntry, bool) {
+		iter := 0
+		return func() (DBEntry, bool) {
+			if iter >= int(n) {
+				return DBEntry{}, false
+			}
+			entry := DBEntry{Index: uint64(iter), Value: db[iter]}
+			iter++
+			return entry, true
+		}
+	}
+
+	serial := NewClient(n, m, keyAlpha, keyBeta)
+	serial.HintInit(getStream())
+
+	for _, workers := range []int{1, 4, 16} {
+		parallel := NewClient(n, m, keyAlpha, keyBeta)
+		parallel.HintInitParallel(getStream(), workers)
+
+		for i := range serial.hints {
+			if parallel.hints[i].Parity != serial.hints[i].Parity {
+				t.Errorf("workers=%d: hint %d parity mismatch: got %v, want %v", workers, i, parallel.hints[i].Parity, serial.hints[i].Parity)
+			}
+		}
+	}
+}
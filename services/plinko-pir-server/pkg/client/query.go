@@ -0,0 +1,116 @@
+package client
+
+// Query is the online phase for a single target: find an unused hint
+// whose index set covers target, mark it used, and return the punctured
+// set (the hint's indices with target removed) for the server to XOR,
+// plus the hint itself so the caller can later recover the value via
+// Reconstruct. Primary hints are tried first (O(1) via the iPRF), falling
+// back to backup hints (O(len(backupHints))) once the primary hint for
+// target has already been consumed.
+func (c *Client) Query(target uint64) ([]uint64, Hint, bool) {
+	hint, kind, ok := c.findUnusedHint(target)
+	if !ok {
+		return nil, Hint{}, false
+	}
+	hint.Used = true
+	return puncture(c.indicesFor(*hint, kind, target), target), *hint, true
+}
+
+// Reconstruct recovers the value at the target index Query was called
+// with, given the server's parity over the punctured set Query returned.
+// hint.Parity is the XOR over the hint's full (unpunctured) index set, so
+// XORing it with the server's answer cancels every entry except target.
+func (c *Client) Reconstruct(parity [4]uint64, hint Hint) [4]uint64 {
+	var value [4]uint64
+	for w := 0; w < 4; w++ {
+		value[w] = parity[w] ^ hint.Parity[w]
+	}
+	return value
+}
+
+// findUnusedHint returns a pointer to the first unused hint covering
+// target, and whether it's the primary hint or a backup hint: the
+// primary hint if it hasn't been consumed yet, else the first matching
+// backup hint. Under StructuredPRP, a not-yet-materialized backup bucket
+// is found in O(1) (a single Permute call picks the bucket); backup hints
+// carrying explicit Indices (every hint under ExplicitIndices, and any
+// ApplyBackupRefresh has added under StructuredPRP) are found by an
+// O(len(backupHints)) scan.
+func (c *Client) findUnusedHint(target uint64) (*Hint, queryKind, bool) {
+	y := c.iprf.F(target)
+	if y < uint64(len(c.hints)) && !c.hints[y].Used {
+		return &c.hints[y], kindPrimary, true
+	}
+
+	if c.hintMode == StructuredPRP && c.backupPRP != nil && c.backupSetSize > 0 {
+		bucket := c.backupPRP.Permute(target) / uint64(c.backupSetSize)
+		if bucket < uint64(len(c.backupHints)) && !c.backupHints[bucket].Used && len(c.backupHints[bucket].Indices) == 0 {
+			return &c.backupHints[bucket], kindBackup, true
+		}
+	}
+
+	for i := range c.backupHints {
+		if c.backupHints[i].Used {
+			continue
+		}
+		for _, idx := range c.backupHints[i].Indices {
+			if idx == target {
+				return &c.backupHints[i], kindBackup, true
+			}
+		}
+	}
+	return nil, kindPrimary, false
+}
+
+// indicesFor returns a hint's full index set. hint.Indices takes priority
+// whenever it's populated: ExplicitIndices backup hints store it
+// directly, and so does any hint ConsumeHint/ApplyBackupRefresh has
+// materialized into a regular hint slot (see refresh.go), regardless of
+// the kind findUnusedHint reported it as. Failing that, kind disambiguates
+// the two remaining cases: a true primary hint's set is the iPRF bin
+// target falls into, while a not-yet-materialized StructuredPRP backup
+// hint's set is reconstructed from target's bucket via backupPRP.Inverse.
+func (c *Client) indicesFor(hint Hint, kind queryKind, target uint64) []uint64 {
+	if len(hint.Indices) > 0 {
+		return hint.Indices
+	}
+	if kind == kindPrimary {
+		return c.iprf.Inverse(c.iprf.F(target))
+	}
+
+	bucket := c.backupPRP.Permute(target) / uint64(c.backupSetSize)
+	return c.structuredBucketIndices(bucket)
+}
+
+// structuredBucketIndices reconstructs a StructuredPRP backup bucket's
+// member indices via backupPRP.Inverse. backupPRP's domain is [0, n), so
+// when backupSetSize doesn't divide n the last bucket runs past n; members
+// at or beyond n don't exist and are skipped, leaving that bucket's set
+// smaller than backupSetSize.
+func (c *Client) structuredBucketIndices(bucket uint64) []uint64 {
+	start := bucket * uint64(c.backupSetSize)
+	indices := make([]uint64, 0, c.backupSetSize)
+	for k := 0; k < c.backupSetSize; k++ {
+		j := start + uint64(k)
+		if j >= c.n {
+			break
+		}
+		indices = append(indices, c.backupPRP.Inverse(j))
+	}
+	return indices
+}
+
+// puncture returns a copy of indices with the first occurrence of target
+// removed.
+func puncture(indices []uint64, target uint64) []uint64 {
+	out := make([]uint64, 0, len(indices))
+	removed := false
+	for _, idx := range indices {
+		if !removed && idx == target {
+			removed = true
+			continue
+		}
+		out = append(out, idx)
+	}
+	return out
+}
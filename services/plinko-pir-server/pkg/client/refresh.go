@@ -0,0 +1,98 @@
+package client
+
+// ConsumeHint retires the hint at regular-hint index y -- typically just
+// after Query has marked it used -- and immediately refreshes slot y by
+// popping a hint off the backup pool, so a later query mapping to the
+// same iPRF bin doesn't need the slower generic backup-hint scan in
+// findUnusedHint. It returns the hint that occupied y before the swap. If
+// the backup pool is empty, y is left unchanged (still Used) and the
+// returned hint is simply hints[y].
+func (c *Client) ConsumeHint(y uint64) Hint {
+	old := c.hints[y]
+
+	last := len(c.backupHints) - 1
+	if last < 0 {
+		return old
+	}
+	backup := c.backupHints[last]
+	c.backupHints = c.backupHints[:last]
+
+	if c.hintMode == StructuredPRP && len(backup.Indices) == 0 {
+		// Not yet materialized: this is one of the original structured
+		// buckets, whose index set indicesFor would otherwise
+		// reconstruct on demand from the *query target*'s bucket. Once
+		// installed as a regular hint, though, queries reaching it come
+		// from targets all over the iPRF bin y, not just this bucket's
+		// own members, so its set has to be baked into Indices now
+		// while the bucket number (its position in backupHints) is
+		// still known.
+		backup.Indices = c.structuredBucketIndices(uint64(last))
+	}
+
+	c.RefreshHint(y, backup)
+	return old
+}
+
+// RefreshHint installs backup as the regular hint at index y -- e.g. one
+// popped by ConsumeHint, or one built from a server's RefreshRequest
+// response via ApplyBackupRefresh. backup.Used is reset to false
+// regardless of its incoming value, since a freshly installed hint is by
+// definition unconsumed.
+func (c *Client) RefreshHint(y uint64, backup Hint) {
+	backup.Used = false
+	c.hints[y] = backup
+}
+
+// BackupBudget returns the number of backup hints remaining in the pool.
+func (c *Client) BackupBudget() int {
+	return len(c.backupHints)
+}
+
+// RefreshRequest describes a StructuredPRP backup bucket the client wants
+// a fresh parity for, to replenish its backup pool without another full
+// database pass: since the bucket's member indices are already
+// computable locally via backupPRP.Inverse, only they need to cross the
+// wire -- the server just XORs them, the same as any other online query.
+type RefreshRequest struct {
+	Bucket  uint64
+	Indices []uint64
+}
+
+// RequestBackupRefresh builds a RefreshRequest for the next
+// never-issued StructuredPRP backup bucket, for the caller to send to the
+// server; pass the response to ApplyBackupRefresh. It panics if hintMode
+// isn't StructuredPRP: ExplicitIndices has no well-defined "next bucket"
+// to draw from, so replenishing it means re-streaming the DB via
+// InitBackupHints instead. It returns ok false once every bucket in
+// backupPRP's domain [0, n) has already been issued -- there is no
+// "next" bucket left to draw from, and drawing one past ceil(n/setSize)
+// would ask structuredBucketIndices for a bucket with no members.
+func (c *Client) RequestBackupRefresh() (RefreshRequest, bool) {
+	if c.hintMode != StructuredPRP {
+		panic("RequestBackupRefresh requires StructuredPRP hint mode")
+	}
+	if c.backupSetSize == 0 {
+		return RefreshRequest{}, false
+	}
+
+	maxBuckets := uint64(maxStructuredBuckets(c.n, c.backupSetSize))
+	if c.nextBackupBucket >= maxBuckets {
+		return RefreshRequest{}, false
+	}
+
+	bucket := c.nextBackupBucket
+	c.nextBackupBucket++
+	return RefreshRequest{Bucket: bucket, Indices: c.structuredBucketIndices(bucket)}, true
+}
+
+// ApplyBackupRefresh appends a new backup hint built from the server's
+// parity response to req, growing the pool by one. The hint carries its
+// indices explicitly (req.Indices is already known), so it behaves like
+// an ExplicitIndices hint from here on regardless of the client's
+// HintMode.
+func (c *Client) ApplyBackupRefresh(req RefreshRequest, parity [4]uint64) {
+	c.backupHints = append(c.backupHints, Hint{
+		Indices: req.Indices,
+		Parity:  parity,
+	})
+}
@@ -0,0 +1,145 @@
+package client
+
+import "testing"
+
+// TestConsumeHintPromotesBackup verifies that after ConsumeHint promotes a
+// StructuredPRP backup bucket into a regular hint slot, queries landing on
+// that slot via the iPRF (not just the original bucket's own targets) are
+// still answered correctly -- i.e. indicesFor picks up the materialized
+// Indices rather than recomputing the iPRF bin.
+func TestConsumeHintPromotesBackup(t *testing.T) {
+	n := uint64(100)
+	db := make([][4]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		db[i][0] = uint64(i)
+	}
+
+	m := uint64(10)
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+
+	c := NewClient(n, m, keyAlpha, keyBeta)
+	c.SetHintMode(StructuredPRP)
+
+	getStream := func() func() (DBEntry, bool) {
+		iter := 0
+		return func() (DBEntry, bool) {
+			if iter >= int(n) {
+				return DBEntry{}, false
+			}
+			entry := DBEntry{Index: uint64(iter), Value: db[iter]}
+			iter++
+			return entry, true
+		}
+	}
+
+	c.OfflineSetup(getStream(), 50, 10)
+
+	target := uint64(55)
+	y := c.iprf.F(target)
+
+	if _, _, ok := c.Query(target); !ok {
+		t.Fatalf("priming query failed")
+	}
+
+	budgetBefore := c.BackupBudget()
+	old := c.ConsumeHint(y)
+	if !old.Used {
+		t.Errorf("ConsumeHint should return the previously-installed (now used) hint")
+	}
+	if c.BackupBudget() != budgetBefore-1 {
+		t.Errorf("ConsumeHint should pop exactly one backup hint, budget went %d -> %d", budgetBefore, c.BackupBudget())
+	}
+	if c.hints[y].Used {
+		t.Errorf("promoted hint should be unused")
+	}
+	if len(c.hints[y].Indices) == 0 {
+		t.Errorf("promoted StructuredPRP backup hint should be materialized with explicit Indices")
+	}
+
+	// Any index the promoted hint covers should now answer correctly
+	// through the regular Query path.
+	probe := c.hints[y].Indices[0]
+	indices, hint, ok := c.Query(probe)
+	if !ok {
+		t.Fatalf("query for promoted hint's own index failed")
+	}
+	var parity [4]uint64
+	for _, idx := range indices {
+		parity[0] ^= db[idx][0]
+	}
+	if val := c.Reconstruct(parity, hint); val[0] != db[probe][0] {
+		t.Errorf("reconstruct after promotion: got %d, want %d", val[0], db[probe][0])
+	}
+}
+
+// TestBackupRefreshRoundTrip exercises RequestBackupRefresh/
+// ApplyBackupRefresh: the client asks for a fresh bucket, a stand-in
+// "server" answers with its parity computed directly from db, and the
+// resulting backup hint is then usable from Query like any other.
+func TestBackupRefreshRoundTrip(t *testing.T) {
+	n := uint64(100)
+	db := make([][4]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		db[i][0] = uint64(i)
+	}
+
+	m := uint64(10)
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+
+	c := NewClient(n, m, keyAlpha, keyBeta)
+	c.SetHintMode(StructuredPRP)
+
+	getStream := func() func() (DBEntry, bool) {
+		iter := 0
+		return func() (DBEntry, bool) {
+			if iter >= int(n) {
+				return DBEntry{}, false
+			}
+			entry := DBEntry{Index: uint64(iter), Value: db[iter]}
+			iter++
+			return entry, true
+		}
+	}
+
+	c.OfflineSetup(getStream(), 5, 10)
+
+	budgetBefore := c.BackupBudget()
+	req, ok := c.RequestBackupRefresh()
+	if !ok {
+		t.Fatalf("RequestBackupRefresh should still have buckets left to draw from")
+	}
+	if len(req.Indices) != 10 {
+		t.Fatalf("refresh request should cover a full bucket of 10, got %d", len(req.Indices))
+	}
+
+	var parity [4]uint64
+	for _, idx := range req.Indices {
+		parity[0] ^= db[idx][0]
+	}
+	c.ApplyBackupRefresh(req, parity)
+
+	if c.BackupBudget() != budgetBefore+1 {
+		t.Errorf("ApplyBackupRefresh should grow the backup pool by one, budget went %d -> %d", budgetBefore, c.BackupBudget())
+	}
+
+	// Consume the primary hint for target first, so the next Query has to
+	// fall through to the freshly-refreshed backup bucket.
+	target := req.Indices[0]
+	if _, _, ok := c.Query(target); !ok {
+		t.Fatalf("priming query for target's primary hint failed")
+	}
+
+	indices, hint, ok := c.Query(target)
+	if !ok {
+		t.Fatalf("query for freshly-refreshed bucket's index failed")
+	}
+	var answer [4]uint64
+	for _, idx := range indices {
+		answer[0] ^= db[idx][0]
+	}
+	if val := c.Reconstruct(answer, hint); val[0] != db[target][0] {
+		t.Errorf("reconstruct after refresh: got %d, want %d", val[0], db[target][0])
+	}
+}
@@ -0,0 +1,385 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"plinko-pir-server/pkg/iprf"
+	"sort"
+)
+
+// Binary layout for a persisted Client, so the offline phase (HintInit +
+// InitBackupHints) only needs to run once per database, not once per
+// process. Mirrors how the circom proving-key binary format avoids
+// rebuilding from the JSON form on every load.
+//
+//	header:       magic[4]="PLNK", version uint16, flags uint16, reserved uint64 (16 bytes)
+//	keys section:     len(keyAlpha) u32, keyAlpha, len(keyBeta) u32, keyBeta
+//	params section:   n u64, m u64, backupCount u32, hintMode byte,
+//	                  backupSetSize u32, nextBackupBucket u64
+//	primaryHints section: m * (parity [4]u64), then ceil(m/8) bytes of used-bits
+//	backupHints section:  backupCount u32, then
+//	                      per hint: parity [4]u64, used byte, index count uvarint,
+//	                      then delta-encoded index uvarints (sorted ascending) --
+//	                      empty for a StructuredPRP hint that's never been
+//	                      materialized (see indicesFor)
+//	footer:       crc32c(body) u32
+//
+// Every section is wrapped in a u32 byte length so a reader can skip a
+// section it doesn't understand in a future version. backupPRP itself
+// isn't persisted: under StructuredPRP it's rebuilt deterministically
+// from keyAlpha/n on load, same as iprf is from keyAlpha/keyBeta/n/m.
+//
+// version 2 added hintMode/backupSetSize/nextBackupBucket to the params
+// section; a version-1 blob decoded as StructuredPRP would silently come
+// back as ExplicitIndices with empty backup-hint index sets, so version 1
+// is no longer accepted.
+const (
+	magic          = "PLNK"
+	headerSize     = 16
+	currentVersion = 2
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// MarshalBinary encodes the client's persisted state: keys, sizing
+// parameters, the hint mode and its StructuredPRP-specific parameters,
+// primary hints, and backup hints. It does not encode
+// transient state such as randSource.
+func (c *Client) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteTo streams the same encoding as MarshalBinary to w.
+func (c *Client) WriteTo(w io.Writer) (int64, error) {
+	var header [headerSize]byte
+	copy(header[0:4], magic)
+	binary.LittleEndian.PutUint16(header[4:6], currentVersion)
+	// header[6:8] flags, header[8:16] reserved: both left zero.
+
+	var body bytes.Buffer
+	writeSection(&body, c.keysSection())
+	writeSection(&body, c.paramsSection())
+	writeSection(&body, c.primaryHintsSection())
+	writeSection(&body, c.backupHintsSection())
+
+	var footer [4]byte
+	binary.LittleEndian.PutUint32(footer[:], crc32.Checksum(body.Bytes(), crcTable))
+
+	n1, err := w.Write(header[:])
+	total := int64(n1)
+	if err != nil {
+		return total, err
+	}
+	n2, err := w.Write(body.Bytes())
+	total += int64(n2)
+	if err != nil {
+		return total, err
+	}
+	n3, err := w.Write(footer[:])
+	total += int64(n3)
+	return total, err
+}
+
+func writeSection(buf *bytes.Buffer, section []byte) {
+	var lenPrefix [4]byte
+	binary.LittleEndian.PutUint32(lenPrefix[:], uint32(len(section)))
+	buf.Write(lenPrefix[:])
+	buf.Write(section)
+}
+
+func (c *Client) keysSection() []byte {
+	var buf bytes.Buffer
+	writeBytes(&buf, c.keyAlpha)
+	writeBytes(&buf, c.keyBeta)
+	return buf.Bytes()
+}
+
+func writeBytes(buf *bytes.Buffer, b []byte) {
+	var lenPrefix [4]byte
+	binary.LittleEndian.PutUint32(lenPrefix[:], uint32(len(b)))
+	buf.Write(lenPrefix[:])
+	buf.Write(b)
+}
+
+func (c *Client) paramsSection() []byte {
+	var buf [33]byte
+	binary.LittleEndian.PutUint64(buf[0:8], c.n)
+	binary.LittleEndian.PutUint64(buf[8:16], c.m)
+	binary.LittleEndian.PutUint32(buf[16:20], uint32(len(c.backupHints)))
+	buf[20] = byte(c.hintMode)
+	binary.LittleEndian.PutUint32(buf[21:25], uint32(c.backupSetSize))
+	binary.LittleEndian.PutUint64(buf[25:33], c.nextBackupBucket)
+	return buf[:]
+}
+
+func (c *Client) primaryHintsSection() []byte {
+	var buf bytes.Buffer
+	for _, hint := range c.hints {
+		writeParity(&buf, hint.Parity)
+	}
+	used := make([]byte, (len(c.hints)+7)/8)
+	for i, hint := range c.hints {
+		if hint.Used {
+			used[i/8] |= 1 << uint(i%8)
+		}
+	}
+	buf.Write(used)
+	return buf.Bytes()
+}
+
+func (c *Client) backupHintsSection() []byte {
+	var buf bytes.Buffer
+	var countBuf [4]byte
+	binary.LittleEndian.PutUint32(countBuf[:], uint32(len(c.backupHints)))
+	buf.Write(countBuf[:])
+
+	for _, hint := range c.backupHints {
+		writeParity(&buf, hint.Parity)
+		if hint.Used {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+
+		indices := append([]uint64(nil), hint.Indices...)
+		sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+		var varintBuf [binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(varintBuf[:], uint64(len(indices)))
+		buf.Write(varintBuf[:n])
+
+		var prev uint64
+		for _, idx := range indices {
+			n := binary.PutUvarint(varintBuf[:], idx-prev)
+			buf.Write(varintBuf[:n])
+			prev = idx
+		}
+	}
+	return buf.Bytes()
+}
+
+func writeParity(buf *bytes.Buffer, parity [4]uint64) {
+	var b [32]byte
+	for w := 0; w < 4; w++ {
+		binary.LittleEndian.PutUint64(b[w*8:w*8+8], parity[w])
+	}
+	buf.Write(b[:])
+}
+
+// UnmarshalBinary decodes a Client previously written by MarshalBinary,
+// replacing all persisted state. Transient fields (randSource) are reset
+// to their NewClient defaults.
+func (c *Client) UnmarshalBinary(data []byte) error {
+	_, err := c.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// ReadFrom is the streaming counterpart to UnmarshalBinary.
+func (c *Client) ReadFrom(r io.Reader) (int64, error) {
+	var header [headerSize]byte
+	n1, err := io.ReadFull(r, header[:])
+	total := int64(n1)
+	if err != nil {
+		return total, fmt.Errorf("client: reading header: %w", err)
+	}
+	if string(header[0:4]) != magic {
+		return total, fmt.Errorf("client: bad magic %q, want %q", header[0:4], magic)
+	}
+	version := binary.LittleEndian.Uint16(header[4:6])
+	if version != currentVersion {
+		return total, fmt.Errorf("client: unsupported version %d, want %d", version, currentVersion)
+	}
+
+	rest, err := io.ReadAll(r)
+	total += int64(len(rest))
+	if err != nil {
+		return total, err
+	}
+	if len(rest) < 4 {
+		return total, fmt.Errorf("client: truncated footer")
+	}
+	body, footer := rest[:len(rest)-4], rest[len(rest)-4:]
+	if want := binary.LittleEndian.Uint32(footer); want != crc32.Checksum(body, crcTable) {
+		return total, fmt.Errorf("client: crc32c mismatch")
+	}
+
+	br := bytes.NewReader(body)
+	keySection, err := readSection(br)
+	if err != nil {
+		return total, fmt.Errorf("client: reading keys section: %w", err)
+	}
+	paramsSection, err := readSection(br)
+	if err != nil {
+		return total, fmt.Errorf("client: reading params section: %w", err)
+	}
+	primarySection, err := readSection(br)
+	if err != nil {
+		return total, fmt.Errorf("client: reading primary hints section: %w", err)
+	}
+	backupSection, err := readSection(br)
+	if err != nil {
+		return total, fmt.Errorf("client: reading backup hints section: %w", err)
+	}
+
+	keyAlpha, keyBeta, err := decodeKeysSection(keySection)
+	if err != nil {
+		return total, err
+	}
+	n, m, backupCount, hintMode, backupSetSize, nextBackupBucket, err := decodeParamsSection(paramsSection)
+	if err != nil {
+		return total, err
+	}
+	hints, err := decodePrimaryHintsSection(primarySection, m)
+	if err != nil {
+		return total, err
+	}
+	backupHints, err := decodeBackupHintsSection(backupSection, backupCount)
+	if err != nil {
+		return total, err
+	}
+
+	c.keyAlpha = keyAlpha
+	c.keyBeta = keyBeta
+	c.n = n
+	c.m = m
+	c.hints = hints
+	c.backupHints = backupHints
+	c.iprf = iprf.New(keyAlpha, keyBeta, n, m)
+	c.randSource = defaultRandSource
+	c.hintMode = hintMode
+	c.backupSetSize = backupSetSize
+	c.nextBackupBucket = nextBackupBucket
+	if c.hintMode == StructuredPRP {
+		c.backupPRP = iprf.NewPRP(backupPRPKey(c.keyAlpha), c.n)
+	}
+
+	return total, nil
+}
+
+func readSection(r *bytes.Reader) ([]byte, error) {
+	var lenPrefix [4]byte
+	if _, err := io.ReadFull(r, lenPrefix[:]); err != nil {
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint32(lenPrefix[:])
+	section := make([]byte, n)
+	if _, err := io.ReadFull(r, section); err != nil {
+		return nil, err
+	}
+	return section, nil
+}
+
+func decodeKeysSection(section []byte) (keyAlpha, keyBeta []byte, err error) {
+	r := bytes.NewReader(section)
+	keyAlpha, err = readBytes(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBeta, err = readBytes(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keyAlpha, keyBeta, nil
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	var lenPrefix [4]byte
+	if _, err := io.ReadFull(r, lenPrefix[:]); err != nil {
+		return nil, err
+	}
+	n := binary.LittleEndian.Uint32(lenPrefix[:])
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func decodeParamsSection(section []byte) (n, m uint64, backupCount uint32, hintMode HintMode, backupSetSize int, nextBackupBucket uint64, err error) {
+	if len(section) != 33 {
+		return 0, 0, 0, 0, 0, 0, fmt.Errorf("client: params section has %d bytes, want 33", len(section))
+	}
+	n = binary.LittleEndian.Uint64(section[0:8])
+	m = binary.LittleEndian.Uint64(section[8:16])
+	backupCount = binary.LittleEndian.Uint32(section[16:20])
+	hintMode = HintMode(section[20])
+	backupSetSize = int(binary.LittleEndian.Uint32(section[21:25]))
+	nextBackupBucket = binary.LittleEndian.Uint64(section[25:33])
+	return n, m, backupCount, hintMode, backupSetSize, nextBackupBucket, nil
+}
+
+func decodePrimaryHintsSection(section []byte, m uint64) ([]Hint, error) {
+	want := int(m)*32 + (int(m)+7)/8
+	if len(section) != want {
+		return nil, fmt.Errorf("client: primary hints section has %d bytes, want %d", len(section), want)
+	}
+
+	hints := make([]Hint, m)
+	for i := range hints {
+		hints[i].Parity = readParity(section[i*32 : i*32+32])
+	}
+	used := section[int(m)*32:]
+	for i := range hints {
+		hints[i].Used = used[i/8]&(1<<uint(i%8)) != 0
+	}
+	return hints, nil
+}
+
+func decodeBackupHintsSection(section []byte, wantCount uint32) ([]Hint, error) {
+	r := bytes.NewReader(section)
+	var countBuf [4]byte
+	if _, err := io.ReadFull(r, countBuf[:]); err != nil {
+		return nil, err
+	}
+	count := binary.LittleEndian.Uint32(countBuf[:])
+	if count != wantCount {
+		return nil, fmt.Errorf("client: backup hints count %d does not match params section count %d", count, wantCount)
+	}
+
+	hints := make([]Hint, count)
+	for i := range hints {
+		var parityBuf [32]byte
+		if _, err := io.ReadFull(r, parityBuf[:]); err != nil {
+			return nil, err
+		}
+		hints[i].Parity = readParity(parityBuf[:])
+
+		usedByte, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		hints[i].Used = usedByte != 0
+
+		indexCount, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		indices := make([]uint64, indexCount)
+		var cur uint64
+		for j := range indices {
+			delta, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			cur += delta
+			indices[j] = cur
+		}
+		hints[i].Indices = indices
+	}
+	return hints, nil
+}
+
+func readParity(b []byte) [4]uint64 {
+	var parity [4]uint64
+	for w := 0; w < 4; w++ {
+		parity[w] = binary.LittleEndian.Uint64(b[w*8 : w*8+8])
+	}
+	return parity
+}
@@ -0,0 +1,77 @@
+package client
+
+import "testing"
+
+// TestMarshalBinaryPreservesStructuredPRP guards against a StructuredPRP
+// client round-tripping through MarshalBinary/UnmarshalBinary as
+// ExplicitIndices: hintMode, backupSetSize, and nextBackupBucket all have
+// to survive the trip, and backupPRP has to get rebuilt from keyAlpha/n,
+// or a reloaded client's backup queries would never match.
+func TestMarshalBinaryPreservesStructuredPRP(t *testing.T) {
+	n := uint64(100)
+	db := make([][4]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		db[i][0] = uint64(i)
+	}
+
+	m := uint64(10)
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+
+	c := NewClient(n, m, keyAlpha, keyBeta)
+	c.SetHintMode(StructuredPRP)
+
+	getStream := func() func() (DBEntry, bool) {
+		iter := 0
+		return func() (DBEntry, bool) {
+			if iter >= int(n) {
+				return DBEntry{}, false
+			}
+			entry := DBEntry{Index: uint64(iter), Value: db[iter]}
+			iter++
+			return entry, true
+		}
+	}
+
+	c.OfflineSetup(getStream(), 5, 10)
+	req, ok := c.RequestBackupRefresh()
+	if !ok {
+		t.Fatalf("RequestBackupRefresh failed before round trip")
+	}
+
+	data, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	reloaded := &Client{}
+	if err := reloaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+
+	if reloaded.hintMode != StructuredPRP {
+		t.Fatalf("hintMode after reload = %v, want StructuredPRP", reloaded.hintMode)
+	}
+	if reloaded.backupSetSize != 10 {
+		t.Errorf("backupSetSize after reload = %d, want 10", reloaded.backupSetSize)
+	}
+	if reloaded.nextBackupBucket != req.Bucket+1 {
+		t.Errorf("nextBackupBucket after reload = %d, want %d", reloaded.nextBackupBucket, req.Bucket+1)
+	}
+	if reloaded.backupPRP == nil {
+		t.Fatalf("backupPRP was not rebuilt on reload")
+	}
+
+	// A backup query should still resolve correctly: consume the primary
+	// hint for some target, then confirm the reloaded client can still
+	// find its matching backup bucket via backupPRP. Bucket 0 is always
+	// populated (backupCount is 5 above), so a member of it is guaranteed
+	// to have a backup hint to fall back on.
+	target := c.backupPRP.Inverse(0)
+	if _, _, ok := reloaded.Query(target); !ok {
+		t.Fatalf("priming query on reloaded client failed")
+	}
+	if _, _, ok := reloaded.Query(target); !ok {
+		t.Errorf("reloaded StructuredPRP client failed to find its backup hint")
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import "plinko-pir-server/pkg/iprf"
+
+// Snapshot is a JSON-friendly view of a Client's persisted state, used by
+// cmd/hintpack to convert between the JSON and binary (MarshalBinary)
+// forms for debugging. It carries the same fields as the binary format
+// and nothing else. BackupPRP itself isn't one of them: it's rebuilt
+// deterministically from KeyAlpha and N (see backupPRPKey), the same way
+// iprf is rebuilt from KeyAlpha/KeyBeta/N/M.
+type Snapshot struct {
+	KeyAlpha         []byte   `json:"keyAlpha"`
+	KeyBeta          []byte   `json:"keyBeta"`
+	N                uint64   `json:"n"`
+	M                uint64   `json:"m"`
+	Hints            []Hint   `json:"hints"`
+	BackupHints      []Hint   `json:"backupHints"`
+	HintMode         HintMode `json:"hintMode"`
+	BackupSetSize    int      `json:"backupSetSize,omitempty"`
+	NextBackupBucket uint64   `json:"nextBackupBucket,omitempty"`
+}
+
+// Snapshot returns a JSON-friendly copy of the client's persisted state.
+func (c *Client) Snapshot() Snapshot {
+	return Snapshot{
+		KeyAlpha:         c.keyAlpha,
+		KeyBeta:          c.keyBeta,
+		N:                c.n,
+		M:                c.m,
+		Hints:            c.hints,
+		BackupHints:      c.backupHints,
+		HintMode:         c.hintMode,
+		BackupSetSize:    c.backupSetSize,
+		NextBackupBucket: c.nextBackupBucket,
+	}
+}
+
+// FromSnapshot rebuilds a Client from a Snapshot, reconstructing the iPRF
+// (and, under StructuredPRP, the backup PRP) and resetting transient
+// state (randSource) to its NewClient default.
+func FromSnapshot(s Snapshot) *Client {
+	c := &Client{
+		iprf:             iprf.New(s.KeyAlpha, s.KeyBeta, s.N, s.M),
+		hints:            s.Hints,
+		backupHints:      s.BackupHints,
+		n:                s.N,
+		m:                s.M,
+		keyAlpha:         s.KeyAlpha,
+		keyBeta:          s.KeyBeta,
+		randSource:       defaultRandSource,
+		hintMode:         s.HintMode,
+		backupSetSize:    s.BackupSetSize,
+		nextBackupBucket: s.NextBackupBucket,
+	}
+	if c.hintMode == StructuredPRP {
+		c.backupPRP = iprf.NewPRP(backupPRPKey(c.keyAlpha), c.n)
+	}
+	return c
+}
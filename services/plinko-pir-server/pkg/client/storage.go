@@ -0,0 +1,60 @@
+package client
+
+import "plinko-pir-server/pkg/storage"
+
+// HintInitBackend is HintInit over a storage.DBBackend instead of a
+// closure, for databases large enough (or remote enough) that restreaming
+// them via a fresh closure per call isn't practical.
+func (c *Client) HintInitBackend(b storage.DBBackend) {
+	it := b.Stream(0, b.Len())
+	c.HintInit(func() (DBEntry, bool) {
+		index, value, ok, err := it.Next()
+		if err != nil || !ok {
+			return DBEntry{}, false
+		}
+		return DBEntry{Index: index, Value: value}, true
+	})
+}
+
+// InitBackupHintsBackend is InitBackupHints over a storage.DBBackend. Where
+// InitBackupHints (and OfflineSetup) stream the whole database once
+// regardless of backup hint count, this uses b.Get for random access, so
+// construction cost is O(setSize*k) point reads for k backup hints
+// instead of O(n) streaming reads.
+func (c *Client) InitBackupHintsBackend(count int, setSize int, b storage.DBBackend) error {
+	c.backupHints = make([]Hint, count)
+
+	for i := 0; i < count; i++ {
+		indices := make([]uint64, setSize)
+		seen := make(map[uint64]bool, setSize)
+		for j := 0; j < setSize; j++ {
+			for {
+				idx := c.randSource(c.n)
+				if !seen[idx] {
+					seen[idx] = true
+					indices[j] = idx
+					break
+				}
+			}
+		}
+
+		var parity [4]uint64
+		for _, idx := range indices {
+			value, err := b.Get(idx)
+			if err != nil {
+				return err
+			}
+			for k := 0; k < 4; k++ {
+				parity[k] ^= value[k]
+			}
+		}
+
+		c.backupHints[i] = Hint{
+			Indices: indices,
+			Parity:  parity,
+			Used:    false,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package client
+
+import "testing"
+
+// TestStructuredBackupHints mirrors TestClientBackupHints under
+// StructuredPRP: the primary hint answers the first query, and once it's
+// consumed a second query for the same target falls back to a backup
+// hint whose membership and index set are derived from backupPRP rather
+// than stored explicitly.
+func TestStructuredBackupHints(t *testing.T) {
+	n := uint64(100)
+	db := make([][4]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		db[i][0] = uint64(i)
+	}
+
+	m := uint64(10)
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+
+	c := NewClient(n, m, keyAlpha, keyBeta)
+	c.SetHintMode(StructuredPRP)
+
+	getStream := func() func() (DBEntry, bool) {
+		iter := 0
+		return func() (DBEntry, bool) {
+			if iter >= int(n) {
+				return DBEntry{}, false
+			}
+			entry := DBEntry{Index: uint64(iter), Value: db[iter]}
+			iter++
+			return entry, true
+		}
+	}
+
+	c.OfflineSetup(getStream(), 50, 10)
+
+	target := uint64(55)
+
+	indices1, hint1, ok := c.Query(target)
+	if !ok {
+		t.Fatalf("first query failed: no hint found")
+	}
+	if !hint1.Used {
+		t.Errorf("primary hint should be marked used")
+	}
+
+	var parity1 [4]uint64
+	for _, idx := range indices1 {
+		parity1[0] ^= db[idx][0]
+	}
+	if val := c.Reconstruct(parity1, hint1); val[0] != db[target][0] {
+		t.Errorf("first query result wrong: got %d, want %d", val[0], db[target][0])
+	}
+
+	indices2, hint2, ok := c.Query(target)
+	if !ok {
+		t.Fatalf("second query failed (no backup hint covers target?)")
+	}
+	if len(hint2.Indices) != 0 {
+		t.Errorf("StructuredPRP backup hint should not store Indices, got %d entries", len(hint2.Indices))
+	}
+	if !hint2.Used {
+		t.Errorf("backup hint should be marked used")
+	}
+	if len(indices2) != 9 {
+		t.Errorf("backup hint request should have setSize-1=9 entries after puncturing, got %d", len(indices2))
+	}
+
+	var parity2 [4]uint64
+	for _, idx := range indices2 {
+		parity2[0] ^= db[idx][0]
+	}
+	if val := c.Reconstruct(parity2, hint2); val[0] != db[target][0] {
+		t.Errorf("second query result wrong: got %d, want %d", val[0], db[target][0])
+	}
+}
@@ -1,10 +1,24 @@
 package client
 
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// updateHintTotal counts UpdateHint invocations so benchmark harnesses can
+// scrape it alongside the server's own query metrics.
+var updateHintTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "plinko_client_update_hint_total",
+	Help: "Total number of Client.UpdateHint invocations.",
+})
+
 // UpdateHint updates the client's hints based on a database change
 // index: the database index that changed
 // delta: the XOR difference (oldValue ^ newValue)
 // This is the O(1) update mechanism enabled by iPRF.
 func (c *Client) UpdateHint(index uint64, delta [4]uint64) {
+	updateHintTotal.Inc()
+
 	// 1. Update Primary Hint
 	// Use iPRF Forward to find which primary hint contains 'index'
 	// F(k, index) -> j
@@ -24,8 +38,25 @@ func (c *Client) UpdateHint(index uint64, delta [4]uint64) {
 	}
 
 	// 2. Update Backup Hints
+	//
+	// A StructuredPRP backup bucket that ConsumeHint hasn't materialized
+	// yet has Indices == nil by design (see HintMode's doc): its
+	// membership is derived on demand from backupPRP rather than stored.
+	// The Indices-scan loop below would silently skip it, leaving its
+	// Parity stale, so route index to its bucket directly the same way
+	// findUnusedHint/indicesFor do.
+	if c.hintMode == StructuredPRP && c.backupPRP != nil && c.backupSetSize > 0 {
+		if bucket := c.backupPRP.Permute(index) / uint64(c.backupSetSize); bucket < uint64(len(c.backupHints)) && len(c.backupHints[bucket].Indices) == 0 {
+			for k := 0; k < 4; k++ {
+				c.backupHints[bucket].Parity[k] ^= delta[k]
+			}
+		}
+	}
+
+	// Any ExplicitIndices-mode backup hint, or a StructuredPRP bucket
+	// ConsumeHint has already materialized into Indices, stores its
+	// member set directly, so a reverse scan finds it regardless of mode.
 	for i := range c.backupHints {
-		// Check if index is in this backup hint
 		for _, idx := range c.backupHints[i].Indices {
 			if idx == index {
 				for k := 0; k < 4; k++ {
@@ -0,0 +1,66 @@
+package client
+
+import "testing"
+
+// TestUpdateHintStructuredBackupBucket covers UpdateHint under
+// StructuredPRP: a delta landing on an index inside a backup bucket that
+// hasn't been materialized yet (Indices still nil) must still reach that
+// bucket's Parity, not be silently dropped.
+func TestUpdateHintStructuredBackupBucket(t *testing.T) {
+	n := uint64(100)
+	db := make([][4]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		db[i][0] = uint64(i)
+	}
+
+	m := uint64(10)
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+
+	c := NewClient(n, m, keyAlpha, keyBeta)
+	c.SetHintMode(StructuredPRP)
+
+	getStream := func() func() (DBEntry, bool) {
+		iter := 0
+		return func() (DBEntry, bool) {
+			if iter >= int(n) {
+				return DBEntry{}, false
+			}
+			entry := DBEntry{Index: uint64(iter), Value: db[iter]}
+			iter++
+			return entry, true
+		}
+	}
+
+	c.OfflineSetup(getStream(), 50, 10)
+
+	// Consume the primary hint so the next query for target falls
+	// through to its (still unmaterialized) StructuredPRP backup bucket.
+	target := uint64(55)
+	if _, _, ok := c.Query(target); !ok {
+		t.Fatalf("priming query failed: no hint found")
+	}
+
+	oldValue := db[target][0]
+	newValue := uint64(999)
+	delta := [4]uint64{oldValue ^ newValue, 0, 0, 0}
+	db[target][0] = newValue
+
+	c.UpdateHint(target, delta)
+
+	indices, hint, ok := c.Query(target)
+	if !ok {
+		t.Fatalf("second query failed (no backup hint covers target?)")
+	}
+	if len(hint.Indices) != 0 {
+		t.Fatalf("expected an unmaterialized StructuredPRP backup bucket, got %d explicit indices", len(hint.Indices))
+	}
+
+	var parity [4]uint64
+	for _, idx := range indices {
+		parity[0] ^= db[idx][0]
+	}
+	if val := c.Reconstruct(parity, hint); val[0] != newValue {
+		t.Errorf("UpdateHint didn't reach the unmaterialized backup bucket: got %d, want %d", val[0], newValue)
+	}
+}
@@ -0,0 +1,16 @@
+package client
+
+import "plinko-pir-server/pkg/updatestream"
+
+// SubscribeUpdates joins the Kafka update stream described by cfg and feeds
+// every received delta into UpdateHint, keeping the client's hints current
+// without re-running the offline phase. The returned Consumer must be
+// closed via its Close method on shutdown.
+func (c *Client) SubscribeUpdates(cfg updatestream.Config) (*updatestream.Consumer, error) {
+	consumer, err := updatestream.NewConsumer(cfg, c.UpdateHint)
+	if err != nil {
+		return nil, err
+	}
+	consumer.Start()
+	return consumer, nil
+}
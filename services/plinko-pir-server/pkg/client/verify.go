@@ -0,0 +1,32 @@
+package client
+
+import "plinko-pir-server/pkg/verify"
+
+// ReconstructVerified checks a server's answer against a previously
+// published verify.VerifierParams before combining it with fullParity,
+// instead of trusting serverParity blindly. subset is the punctured set
+// the server actually answered -- the same set Query returns alongside
+// the hint used, not the hint's full (unpunctured) bin -- since that's
+// what the server's opening proof attests serverParity sums to.
+// fullParity is the hint's own parity over its full index set.
+// serverParity and fullParity are verify.FieldParity, not [4]uint64: each
+// is a sum over many entries, which overflows 64 bits per word well
+// before it has any chance of overflowing the scalar field, so the
+// accumulated parity itself can't be represented as a plain hint's
+// narrower type.
+//
+// Verified mode combines shares via field addition rather than XOR: plain
+// hint reconstruction stays bitwise-XOR based (see offline.go, update.go)
+// since Plinko's O(1) updates depend on GF(2) algebra, but a Pedersen
+// commitment is only additively homomorphic, so a verified-mode hint's
+// parity must be accumulated the same way the opening proof checks (see
+// verify.SumParity) and recombined by subtraction, not XOR (see
+// verify.RecoverPunctured). A deployment that wants both properties keeps
+// two parallel databases, one per combination rule, and two parallel
+// hint sets.
+func (c *Client) ReconstructVerified(subset []uint64, serverParity, fullParity verify.FieldParity, params verify.VerifierParams, proof *verify.Opening) ([4]uint64, bool) {
+	if !verify.Verify(params, subset, serverParity, proof) {
+		return [4]uint64{}, false
+	}
+	return verify.RecoverPunctured(fullParity, serverParity), true
+}
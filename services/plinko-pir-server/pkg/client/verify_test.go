@@ -0,0 +1,89 @@
+package client
+
+import (
+	"testing"
+
+	"plinko-pir-server/pkg/storage"
+	"plinko-pir-server/pkg/verify"
+)
+
+// TestReconstructVerifiedRoundTrip exercises the full verified-query
+// path: commit a database, open a proof over a punctured subset (not the
+// full bin), and recover the punctured index's value through
+// ReconstructVerified.
+func TestReconstructVerifiedRoundTrip(t *testing.T) {
+	db := make([][4]uint64, 8)
+	for i := range db {
+		db[i] = [4]uint64{uint64(i) * 7, uint64(i) + 100, 0, 0}
+	}
+
+	backend := storage.NewMemoryBackend(db)
+	commitment, err := verify.Commit(backend)
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	full := []uint64{1, 3, 5, 6}
+	target := uint64(5)
+	punctured := make([]uint64, 0, len(full)-1)
+	for _, idx := range full {
+		if idx != target {
+			punctured = append(punctured, idx)
+		}
+	}
+
+	fullParity, err := verify.SumParity(backend, full)
+	if err != nil {
+		t.Fatalf("SumParity(full): %v", err)
+	}
+	serverParity, err := verify.SumParity(backend, punctured)
+	if err != nil {
+		t.Fatalf("SumParity(punctured): %v", err)
+	}
+	proof := commitment.Open(punctured, serverParity)
+
+	c := &Client{}
+	value, ok := c.ReconstructVerified(punctured, serverParity, fullParity, commitment.Params, proof)
+	if !ok {
+		t.Fatalf("ReconstructVerified rejected a valid proof")
+	}
+	if value != db[target] {
+		t.Errorf("ReconstructVerified: got %v, want %v", value, db[target])
+	}
+}
+
+// TestReconstructVerifiedRejectsTamperedParity confirms a server that
+// lies about the punctured parity fails verification rather than having
+// ReconstructVerified silently hand back a wrong value.
+func TestReconstructVerifiedRejectsTamperedParity(t *testing.T) {
+	db := make([][4]uint64, 8)
+	for i := range db {
+		db[i] = [4]uint64{uint64(i) * 7, 0, 0, 0}
+	}
+
+	backend := storage.NewMemoryBackend(db)
+	commitment, err := verify.Commit(backend)
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	full := []uint64{0, 2, 4}
+	punctured := []uint64{0, 2}
+	fullParity, err := verify.SumParity(backend, full)
+	if err != nil {
+		t.Fatalf("SumParity(full): %v", err)
+	}
+	serverParity, err := verify.SumParity(backend, punctured)
+	if err != nil {
+		t.Fatalf("SumParity(punctured): %v", err)
+	}
+	proof := commitment.Open(punctured, serverParity)
+
+	tampered := serverParity
+	tampered[0][31]++
+
+	c := &Client{}
+	if _, ok := c.ReconstructVerified(punctured, tampered, fullParity, commitment.Params, proof); ok {
+		t.Errorf("ReconstructVerified should reject a tampered parity")
+	}
+}
@@ -0,0 +1,29 @@
+// Package dbsource abstracts where the server's 32-byte-per-entry database
+// comes from, so loadServer can stream entries from a local file, a
+// Postgres table, or anything else that implements Source.
+package dbsource
+
+import "context"
+
+// EntrySize is the on-disk/on-wire size of a single database entry: four
+// little-endian uint64 words.
+const EntrySize = 32
+
+// Entry is a single decoded database row.
+type Entry struct {
+	Index uint64
+	Value [4]uint64
+}
+
+// Source streams database entries in index order. Implementations should
+// decode and hand off one entry at a time rather than buffering the whole
+// database, so the server's memory footprint is proportional to one copy
+// of the data, not two.
+type Source interface {
+	// Len returns the total number of entries.
+	Len(ctx context.Context) (uint64, error)
+	// Stream calls visit once per entry, in increasing index order.
+	// Iteration stops at the first error returned by visit.
+	Stream(ctx context.Context, visit func(Entry) error) error
+	Close() error
+}
@@ -0,0 +1,54 @@
+package dbsource
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// FileSource reads entries from a flat file of back-to-back 32-byte
+// records, the format produced by tools/convert_db.go.
+type FileSource struct {
+	data []byte
+}
+
+// NewFileSource reads the entire file at path into memory. This matches
+// the original loadServer behaviour and remains the right choice for
+// databases that comfortably fit in RAM; PostgresSource is the
+// streaming-friendly alternative for larger datasets.
+func NewFileSource(path string) (*FileSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%EntrySize != 0 {
+		return nil, fmt.Errorf("dbsource: file size %d is not a multiple of %d", len(data), EntrySize)
+	}
+	return &FileSource{data: data}, nil
+}
+
+func (f *FileSource) Len(ctx context.Context) (uint64, error) {
+	return uint64(len(f.data) / EntrySize), nil
+}
+
+func (f *FileSource) Stream(ctx context.Context, visit func(Entry) error) error {
+	entryCount := len(f.data) / EntrySize
+	for i := 0; i < entryCount; i++ {
+		var entry Entry
+		entry.Index = uint64(i)
+		for j := 0; j < 4; j++ {
+			offset := i*EntrySize + j*8
+			entry.Value[j] = binary.LittleEndian.Uint64(f.data[offset : offset+8])
+		}
+		if err := visit(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *FileSource) Close() error {
+	f.data = nil
+	return nil
+}
@@ -0,0 +1,110 @@
+package dbsource
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"plinko-pir-server/pkg/updatestream"
+)
+
+// PostgresSource streams 32-byte entries from a Postgres table, so the
+// server can front a real transactional store instead of a static file.
+type PostgresSource struct {
+	pool  *pgxpool.Pool
+	table string
+}
+
+// NewPostgresSource connects to connString and reads entries from table,
+// which must have an "idx bigint" and a "value bytea" (32-byte) column.
+func NewPostgresSource(ctx context.Context, connString, table string) (*PostgresSource, error) {
+	pool, err := pgxpool.New(ctx, connString)
+	if err != nil {
+		return nil, err
+	}
+	return &PostgresSource{pool: pool, table: table}, nil
+}
+
+func (s *PostgresSource) Len(ctx context.Context) (uint64, error) {
+	var count uint64
+	err := s.pool.QueryRow(ctx, fmt.Sprintf("SELECT count(*) FROM %s", s.table)).Scan(&count)
+	return count, err
+}
+
+// Stream issues a single ordered query and decodes rows one at a time as
+// they're read off the wire, so the full table is never buffered in Go
+// memory before the binary.LittleEndian decode into the caller's flat
+// []uint64.
+func (s *PostgresSource) Stream(ctx context.Context, visit func(Entry) error) error {
+	rows, err := s.pool.Query(ctx, fmt.Sprintf("SELECT idx, value FROM %s ORDER BY idx", s.table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var idx uint64
+		var raw []byte
+		if err := rows.Scan(&idx, &raw); err != nil {
+			return err
+		}
+		if len(raw) != EntrySize {
+			return fmt.Errorf("dbsource: entry %d has %d bytes, want %d", idx, len(raw), EntrySize)
+		}
+
+		entry := Entry{Index: idx}
+		for j := 0; j < 4; j++ {
+			entry.Value[j] = binary.LittleEndian.Uint64(raw[j*8 : j*8+8])
+		}
+		if err := visit(entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *PostgresSource) Close() error {
+	s.pool.Close()
+	return nil
+}
+
+// ListenForUpdates subscribes to a Postgres NOTIFY channel and invokes
+// handler for every notification, decoding the payload with the same
+// (index, delta) wire format as the Kafka update stream in
+// pkg/updatestream, so server.go can drive Client.UpdateHint from either
+// transport with one code path. The payload is expected to be hex-encoded,
+// since NOTIFY payloads are text. Blocks until ctx is cancelled.
+func (s *PostgresSource) ListenForUpdates(ctx context.Context, channel string, handler func(index uint64, delta [4]uint64)) error {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, fmt.Sprintf("LISTEN %s", channel)); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		raw, err := hex.DecodeString(notification.Payload)
+		if err != nil {
+			continue
+		}
+		index, delta, err := updatestream.DecodeDelta(raw)
+		if err != nil {
+			continue
+		}
+		handler(index, delta)
+	}
+}
@@ -74,6 +74,31 @@ func TestPRP(t *testing.T) {
 	}
 }
 
+func TestPRPWithRounds(t *testing.T) {
+	key := make([]byte, 16)
+	n := uint64(1000)
+
+	for _, rounds := range []int{2, 4, 8} {
+		prp := NewPRPWithRounds(key, n, rounds)
+		for x := uint64(0); x < n; x++ {
+			y := prp.Permute(x)
+			if y >= n {
+				t.Errorf("rounds=%d: Permute(%d) = %d, want < %d", rounds, x, y, n)
+			}
+			if inv := prp.Inverse(y); inv != x {
+				t.Errorf("rounds=%d: Inverse(Permute(%d)) = %d, want %d", rounds, x, inv, x)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NewPRPWithRounds(rounds=1) should have panicked")
+		}
+	}()
+	NewPRPWithRounds(key, n, 1)
+}
+
 func TestIPRF(t *testing.T) {
 	key1 := make([]byte, 16) // PRP key
 	key2 := make([]byte, 16) // PMNS key
@@ -129,156 +129,242 @@ func (p *PMNS) children(parent node) (node, node, uint64) {
 	return left, right, s
 }
 
-// sampleBinomial samples from Binomial(n, num/denom) using PRF
+// sampleBinomial samples s ~ Binomial(n, num/denom) using a PRF stream
+// keyed on seedNode, so S and SInverse (which reach the same tree node
+// from opposite directions) always agree on the sampled value.
 func (p *PMNS) sampleBinomial(n, num, denom uint64, seedNode node) uint64 {
 	if n == 0 {
 		return 0
 	}
-
-	// Generate pseudorandom float in [0, 1)
-	// We use the node parameters to seed the PRF
-	// Input: "PMNS" || start || count || low || high
-	var input [32]byte
-	binary.BigEndian.PutUint64(input[0:], seedNode.start)
-	binary.BigEndian.PutUint64(input[8:], seedNode.count)
-	binary.BigEndian.PutUint64(input[16:], seedNode.low)
-	binary.BigEndian.PutUint64(input[24:], seedNode.high)
-
-	// Encrypt to get randomness
-	// var output [32]byte // Unused
-	// Actually AES block size is 16 bytes. Let's just use 16 bytes.
-	var blockOut [16]byte
-	p.block.Encrypt(blockOut[:], input[:16]) // Encrypt first half
-	// XOR with second half to mix? Or just encrypt a counter.
-	// For simplicity and determinism, let's just encrypt the struct bytes.
-	// But we need 16 bytes input.
-	// Let's hash the node to 16 bytes or just use a counter mode if we needed more.
-	// For now, let's just encrypt the first 16 bytes of the struct representation (start, count).
-	// Wait, low and high are also important for uniqueness.
-	// Let's use a hash or just multiple rounds.
-	// Simple approach: Encrypt(start ^ low) and Encrypt(count ^ high) and XOR?
-
-	// Better: Encrypt(Hash(node))
-	// But we want to avoid heavy deps if possible.
-	// Let's just use a simple mixing:
-	var iv [16]byte
-	binary.BigEndian.PutUint64(iv[0:], seedNode.low)
-	binary.BigEndian.PutUint64(iv[8:], seedNode.high)
-
-	// XOR input with IV
-	for i := 0; i < 16; i++ {
-		input[i] ^= iv[i]
-	}
-
-	p.block.Encrypt(blockOut[:], input[:16])
-
-	// Convert to float for binomial sampling
-	// This is a simplified binomial sampling.
-	// For large n, we should use normal approximation or similar.
-	// For the paper's exact distribution, we need to be careful.
-	// The paper says: s <- Binomial(count, p; F(k, node))
-
-	// Inverse Transform Sampling for Binomial is slow for large n.
-	// Normal approximation is standard for large n.
-
 	prob := float64(num) / float64(denom)
+	stream := newPRFStream(p.block, seedNode)
+	return sampleBinomialExact(n, prob, stream)
+}
 
-	// Use 64 bits of randomness for uniform float
-	randVal := binary.BigEndian.Uint64(blockOut[:8])
-	u := float64(randVal) / float64(math.MaxUint64)
+// prfStream derives successive uniform draws from F_k(node) || F_k(node,
+// 1) || F_k(node, 2) || ..., via AES-CTR with a counter that increments
+// once per draw (including on a rejection-sampling retry), so no two
+// draws for the same node, or across sibling nodes (which have distinct
+// (low, high) and therefore a distinct stream), ever repeat.
+//
+// (low, high) alone identifies a node's position in the recursion tree:
+// the split point mid = (low+high)/2 depends only on them, never on
+// (start, count), so they're sufficient to keep streams for distinct
+// tree nodes independent.
+type prfStream struct {
+	block   cipher.Block
+	nodeKey [16]byte
+	counter uint64
+}
 
-	return inverseBinomial(n, prob, u)
+func newPRFStream(block cipher.Block, seedNode node) *prfStream {
+	var key [16]byte
+	binary.BigEndian.PutUint64(key[0:8], seedNode.low)
+	binary.BigEndian.PutUint64(key[8:16], seedNode.high)
+	return &prfStream{block: block, nodeKey: key}
 }
 
-// inverseBinomial computes the inverse CDF of the binomial distribution
-// This is a placeholder for a robust implementation.
-// For large n, we use Normal approximation.
-func inverseBinomial(n uint64, p float64, u float64) uint64 {
-	if n > 50 {
-		// Normal approximation
-		mean := float64(n) * p
-		stdDev := math.Sqrt(float64(n) * p * (1 - p))
-
-		// Inverse Error Function approximation or Box-Muller?
-		// Actually we have u uniform.
-		// z = InverseNormal(u)
-		// k = mean + z * stdDev
-
-		z := math.Sqrt(-2.0*math.Log(u)) * math.Cos(2.0*math.Pi*u) // Box-Muller requires 2 randoms
-		// We only have 1 u.
-		// Let's use a simple approximation for InverseNormal (probit function)
-		// or just use a library if available. Go math doesn't have Erfinv.
-
-		// For this PoC, let's use a very simple approximation or just return mean (which is bad for distribution).
-		// Let's implement a simple quantile function for Normal.
-
-		// Beasley-Springer-Moro algorithm for inverse normal CDF
-		z = normalInv(u)
-		res := math.Round(mean + z*stdDev)
-		if res < 0 {
-			return 0
-		}
-		if res > float64(n) {
-			return n
-		}
-		return uint64(res)
+// next returns the next 64 pseudorandom bits in the stream.
+func (s *prfStream) next() uint64 {
+	var in [16]byte
+	copy(in[:], s.nodeKey[:])
+	var ctr [8]byte
+	binary.BigEndian.PutUint64(ctr[:], s.counter)
+	for i := 0; i < 8; i++ {
+		in[8+i] ^= ctr[i]
 	}
+	s.counter++
 
-	// Exact calculation for small n
-	var sum float64 = 0
-	for k := uint64(0); k <= n; k++ {
-		prob := binomialProb(n, k, p)
-		sum += prob
-		if sum >= u {
-			return k
-		}
-	}
-	return n
+	var out [16]byte
+	s.block.Encrypt(out[:], in[:])
+	return binary.BigEndian.Uint64(out[:8])
 }
 
-func binomialProb(n, k uint64, p float64) float64 {
-	// nCk * p^k * (1-p)^(n-k)
-	// Use log gamma for stability if needed, but for n<=50 direct is okay
-	combinations := 1.0
-	for i := uint64(0); i < k; i++ {
-		combinations *= float64(n-i) / float64(i+1)
-	}
-	return combinations * math.Pow(p, float64(k)) * math.Pow(1-p, float64(n-k))
+// uniform returns the next draw as a uniform float64 in [0, 1).
+func (s *prfStream) uniform() float64 {
+	return float64(s.next()) / (float64(math.MaxUint64) + 1)
 }
 
-// normalInv approximates the inverse standard normal CDF
-// Source: Beasley-Springer-Moro algorithm
-func normalInv(p float64) float64 {
-	if p <= 0 || p >= 1 {
+// sampleBinomialExact draws from Binomial(n, p): the inversion method
+// (recurrence P(k+1) = P(k)*(n-k)/(k+1)*p/(1-p), computed in log-space)
+// for n*p <= 10, where it needs only one uniform per sample, and the BTPE
+// rejection algorithm (Kachitvichyanukul & Schmeiser 1988) otherwise,
+// where inversion's expected O(n*p) PMF steps per sample would be too
+// slow.
+func sampleBinomialExact(n uint64, p float64, stream *prfStream) uint64 {
+	if p <= 0 {
 		return 0
 	}
+	if p >= 1 {
+		return n
+	}
 
-	a := [4]float64{
-		2.50662823884, -18.61500062529, 41.39119773534, -25.44106049637,
+	// Both samplers assume p <= 0.5; sample from the reflected
+	// distribution and flip back when that doesn't hold.
+	flip := p > 0.5
+	q := p
+	if flip {
+		q = 1 - p
 	}
-	b := [4]float64{
-		-8.47351093090, 23.08336743743, -21.06224101826, 3.13082909833,
+
+	var k uint64
+	if float64(n)*q <= 10 {
+		k = inversionBinomial(n, q, stream)
+	} else {
+		k = btpeBinomial(n, q, stream)
 	}
-	c := [9]float64{
-		0.3374754822726147, 0.9761690190917186, 0.1607979714918209,
-		0.0276438810333863, 0.0038405729373609, 0.0003951896511919,
-		0.0000321767881768, 0.0000002888167364, 0.0000003960315187,
+
+	if flip {
+		return n - k
 	}
+	return k
+}
 
-	y := p - 0.5
-	if math.Abs(y) < 0.42 {
-		r := y * y
-		return y * (((a[3]*r+a[2])*r+a[1])*r + a[0]) / ((((b[3]*r+b[2])*r+b[1])*r+b[0])*r + 1)
+// inversionBinomial is the inverse-CDF method: walk the PMF upward from
+// P(0) = (1-p)^n via the standard recurrence until the running CDF
+// exceeds a single uniform draw u. The PMF terms are accumulated in
+// log-space and exponentiated just before use, so the recurrence stays
+// numerically stable even for the smallest terms in the tail.
+func inversionBinomial(n uint64, p float64, stream *prfStream) uint64 {
+	q := 1 - p
+	u := stream.uniform()
+
+	logTerm := float64(n) * math.Log(q)
+	cdf := math.Exp(logTerm)
+	var k uint64
+	for cdf < u && k < n {
+		logTerm += math.Log(float64(n-k)) - math.Log(float64(k+1)) + math.Log(p) - math.Log(q)
+		cdf += math.Exp(logTerm)
+		k++
 	}
+	return k
+}
 
-	r := p
-	if y > 0 {
-		r = 1 - p
+// btpeBinomial implements the BTPE (Binomial, Triangle, Parallelogram,
+// Exponential) algorithm: a triangular envelope covers the body of the
+// distribution, parallelogram and exponential envelopes cover the left
+// and right tails, and a squeeze step avoids the exact PMF evaluation on
+// most draws. Needs ~2.5 uniforms per sample on average, against
+// inversion's O(n*p). Ported from Kachitvichyanukul & Schmeiser (1988)
+// ACM TOMS 14(2), the algorithm behind numpy's legacy binomial sampler.
+func btpeBinomial(n uint64, p float64, stream *prfStream) uint64 {
+	nf := float64(n)
+	q := 1 - p
+	npq := nf * p * q
+
+	ffm := nf*p + p
+	m := math.Floor(ffm)
+	p1 := math.Floor(2.195*math.Sqrt(npq)-4.6*q) + 0.5
+	xm := m + 0.5
+	xl := xm - p1
+	xr := xm + p1
+	c := 0.134 + 20.5/(15.3+m)
+	a := (ffm - xl) / (ffm - xl*p)
+	laml := a * (1 + a/2)
+	a = (xr - ffm) / (xr * q)
+	lamr := a * (1 + a/2)
+	p2 := p1 * (1 + 2*c)
+	p3 := p2 + c/laml
+	p4 := p3 + c/lamr
+
+	for {
+		u := stream.uniform() * p4
+		v := stream.uniform()
+
+		var y float64
+		if u <= p1 {
+			y = xm - p1*v + u
+			return finishBTPE(n, y)
+		}
+
+		if u <= p2 {
+			x := xl + (u-p1)/c
+			v = v*c + 1 - math.Abs(m-x+0.5)/p1
+			if v > 1 || v <= 0 {
+				continue
+			}
+			y = math.Floor(x)
+		} else if u <= p3 {
+			y = math.Floor(xl + math.Log(v)/laml)
+			if y < 0 {
+				continue
+			}
+			v = v * (u - p2) * laml
+		} else {
+			y = math.Floor(xr - math.Log(v)/lamr)
+			if y > nf {
+				continue
+			}
+			v = v * (u - p3) * lamr
+		}
+
+		k := math.Abs(y - m)
+		if k <= 20 || k >= npq/2-1 {
+			// Squeeze: accept/reject via the exact PMF ratio,
+			// walked incrementally from m to y.
+			s := p / q
+			a := s * (nf + 1)
+			f := 1.0
+			switch {
+			case m < y:
+				for i := m + 1; i <= y; i++ {
+					f *= a/i - s
+				}
+			case m > y:
+				for i := y + 1; i <= m; i++ {
+					f /= a/i - s
+				}
+			}
+			if v > f {
+				continue
+			}
+			return finishBTPE(n, y)
+		}
+
+		// Fast acceptance/rejection via a normal-tail bound before
+		// falling back to the exact (but more expensive) check.
+		rho := (k / npq) * ((k*(k/3+0.625)+0.1666666666666)/npq + 0.5)
+		t := -k * k / (2 * npq)
+		logV := math.Log(v)
+		if logV < t-rho {
+			return finishBTPE(n, y)
+		}
+		if logV > t+rho {
+			continue
+		}
+
+		x1 := y + 1
+		f1 := m + 1
+		z := nf + 1 - m
+		w := nf - y + 1
+		x2 := x1 * x1
+		f2 := f1 * f1
+		z2 := z * z
+		w2 := w * w
+		bound := xm*math.Log(f1/x1) + (nf-m+0.5)*math.Log(z/w) +
+			(y-m)*math.Log(w*p/(x1*q)) +
+			stirlingCorrection(f1, f2) +
+			stirlingCorrection(z, z2) +
+			stirlingCorrection(x1, x2) +
+			stirlingCorrection(w, w2)
+		if logV <= bound {
+			return finishBTPE(n, y)
+		}
 	}
-	r = math.Log(-math.Log(r))
-	x := c[0] + r*(c[1]+r*(c[2]+r*(c[3]+r*(c[4]+r*(c[5]+r*(c[6]+r*(c[7]+r*c[8])))))))
+}
+
+// stirlingCorrection is one term of De Moivre's series correcting
+// Stirling's approximation, as used in BTPE's final acceptance test.
+func stirlingCorrection(denom, denomSquared float64) float64 {
+	return (13860.0 - (462.0-(132.0-(99.0-140.0/denomSquared)/denomSquared)/denomSquared)/denomSquared) / denom / 166320.0
+}
+
+func finishBTPE(n uint64, y float64) uint64 {
 	if y < 0 {
-		return -x
+		return 0
+	}
+	if y > float64(n) {
+		return n
 	}
-	return x
+	return uint64(y)
 }
@@ -0,0 +1,98 @@
+package iprf
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"math"
+	"testing"
+)
+
+// TestSampleBinomialDistribution checks sampleBinomialExact's output
+// against the binomial distribution's mean and standard deviation: the
+// sample mean must lie within 3 standard errors of n*p, and the sample
+// standard deviation within 5% (plus 0.05) of sqrt(n*p*(1-p)). It does
+// not compare quantiles of the sampled distribution.
+func TestSampleBinomialDistribution(t *testing.T) {
+	cases := []struct {
+		n uint64
+		p float64
+	}{
+		{n: 20, p: 0.05},  // n*p < 10: inversion path
+		{n: 100, p: 0.5},  // n*p > 10: BTPE path
+		{n: 1000, p: 0.01},
+		{n: 1000, p: 0.9}, // p > 0.5: reflected BTPE path
+	}
+
+	const samples = 1_000_000
+	block := newTestAESBlock(t)
+
+	for _, tc := range cases {
+		mean := float64(tc.n) * tc.p
+		stdDev := math.Sqrt(float64(tc.n) * tc.p * (1 - tc.p))
+
+		sum, sumSq := 0.0, 0.0
+		for i := uint64(0); i < samples; i++ {
+			stream := newPRFStream(block, node{low: i, high: i + tc.n})
+			k := float64(sampleBinomialExact(tc.n, tc.p, stream))
+			sum += k
+			sumSq += k * k
+		}
+
+		sampleMean := sum / samples
+		sampleVar := sumSq/samples - sampleMean*sampleMean
+		sampleStdDev := math.Sqrt(sampleVar)
+
+		// Standard error of the sample mean, so the 3-sigma band
+		// scales with the sample count rather than the distribution
+		// itself.
+		meanTolerance := 3 * stdDev / math.Sqrt(samples)
+		if math.Abs(sampleMean-mean) > meanTolerance {
+			t.Errorf("n=%d p=%.3f: sample mean %.4f, want %.4f +/- %.4f", tc.n, tc.p, sampleMean, mean, meanTolerance)
+		}
+
+		// The sampled standard deviation should be close to the
+		// theoretical one; loose tolerance since this is itself an
+		// estimate from a finite sample.
+		if math.Abs(sampleStdDev-stdDev) > 0.05*stdDev+0.05 {
+			t.Errorf("n=%d p=%.3f: sample stddev %.4f, want ~%.4f", tc.n, tc.p, sampleStdDev, stdDev)
+		}
+	}
+}
+
+// TestSInverseConsistencyExhaustive checks S(SInverse(y)) == y for every
+// bin y, exhaustively, across a range of (n, m) pairs. This is the
+// regime where the old normal-approximation sampler could disagree with
+// itself: S and SInverse walking the same recursion tree from opposite
+// directions must sample the identical s at every node.
+func TestSInverseConsistencyExhaustive(t *testing.T) {
+	key := make([]byte, 16)
+	key[0] = 0x42
+
+	for _, n := range []uint64{1, 2, 3, 7, 16, 100, 513, 2048} {
+		for _, m := range []uint64{1, 2, 3, 5, 17, 64} {
+			if m > n {
+				continue
+			}
+			pmns := NewPMNS(key, n, m)
+			for y := uint64(0); y < m; y++ {
+				start, count := pmns.SInverse(y)
+				for x := start; x < start+count; x++ {
+					if got := pmns.S(x); got != y {
+						t.Fatalf("n=%d m=%d: S(SInverse(%d)) disagrees at x=%d: S(x)=%d, want %d", n, m, y, x, got, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+func newTestAESBlock(t *testing.T) cipher.Block {
+	t.Helper()
+	key := make([]byte, 16)
+	key[0] = 0x7a
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return block
+}
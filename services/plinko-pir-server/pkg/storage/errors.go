@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrOutOfRange is returned by Get for an index >= Len.
+var ErrOutOfRange = errors.New("storage: index out of range")
+
+func decodeEntry(buf []byte) [4]uint64 {
+	var value [4]uint64
+	for w := 0; w < 4; w++ {
+		value[w] = binary.LittleEndian.Uint64(buf[w*8 : w*8+8])
+	}
+	return value
+}
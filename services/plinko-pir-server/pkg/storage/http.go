@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// httpRangeReaderAt is an io.ReaderAt over a single HTTP object (an S3
+// object URL, a presigned URL, anything serving Range requests), issuing
+// one "Range: bytes=..." request per ReadAt call.
+type httpRangeReaderAt struct {
+	client *http.Client
+	url    string
+}
+
+func (r *httpRangeReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	req, err := http.NewRequest(http.MethodGet, r.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, fmt.Errorf("storage: range request returned status %d, want %d", resp.StatusCode, http.StatusPartialContent)
+	}
+	return io.ReadFull(resp.Body, p)
+}
+
+// NewHTTPRangeBackend builds a DBBackend over a database stored as a flat
+// file at url (e.g. an S3 object), fetched on demand via HTTP Range
+// requests. n is the entry count.
+//
+// Stream relies on ReaderAtBackend sizing its section to the requested
+// [from, to) range, so callers that size a Stream call to one PMNS bin
+// (see iprf.PMNS.SInverse, whose returned (start, count) pair is exactly
+// such a contiguous range) turn "read a hint's worth of entries" into a
+// single Range request instead of one per entry, for bins of up to
+// streamChunkBytes; a larger range takes one request per chunk.
+func NewHTTPRangeBackend(client *http.Client, url string, n uint64) *ReaderAtBackend {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return NewReaderAtBackend(&httpRangeReaderAt{client: client, url: url}, n)
+}
@@ -0,0 +1,50 @@
+package storage
+
+// MemoryBackend is an in-memory DBBackend, primarily useful for tests and
+// for databases small enough to load wholesale.
+type MemoryBackend struct {
+	entries [][4]uint64
+}
+
+// NewMemoryBackend wraps entries as a DBBackend. entries is not copied.
+func NewMemoryBackend(entries [][4]uint64) *MemoryBackend {
+	return &MemoryBackend{entries: entries}
+}
+
+func (m *MemoryBackend) Len() uint64 {
+	return uint64(len(m.entries))
+}
+
+func (m *MemoryBackend) Get(i uint64) ([4]uint64, error) {
+	if i >= uint64(len(m.entries)) {
+		return [4]uint64{}, ErrOutOfRange
+	}
+	return m.entries[i], nil
+}
+
+func (m *MemoryBackend) Stream(from, to uint64) Iterator {
+	return &memoryIterator{entries: m.entries, cur: from, to: to}
+}
+
+func (m *MemoryBackend) Close() error {
+	return nil
+}
+
+type memoryIterator struct {
+	entries [][4]uint64
+	cur, to uint64
+}
+
+func (it *memoryIterator) Next() (uint64, [4]uint64, bool, error) {
+	if it.cur >= it.to || it.cur >= uint64(len(it.entries)) {
+		return 0, [4]uint64{}, false, nil
+	}
+	index := it.cur
+	value := it.entries[index]
+	it.cur++
+	return index, value, true, nil
+}
+
+func (it *memoryIterator) Close() error {
+	return nil
+}
@@ -0,0 +1,93 @@
+//go:build !windows
+
+package storage
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// MmapFileBackend is a DBBackend over the flat 32-byte-per-entry file
+// format produced by tools/convert_db.go, backed by an mmap'd view of the
+// file rather than a full read into the heap. Get and Stream both read
+// straight out of the mapping, so the OS page cache (not Go's GC) carries
+// the cost of a large database.
+type MmapFileBackend struct {
+	data []byte
+}
+
+// NewMmapFileBackend maps path into memory read-only.
+func NewMmapFileBackend(path string) (*MmapFileBackend, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := fi.Size()
+	if size%EntrySize != 0 {
+		return nil, fmt.Errorf("storage: file size %d is not a multiple of %d", size, EntrySize)
+	}
+	if size == 0 {
+		return &MmapFileBackend{}, nil
+	}
+
+	data, err := unix.Mmap(int(f.Fd()), 0, int(size), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("storage: mmap: %w", err)
+	}
+	return &MmapFileBackend{data: data}, nil
+}
+
+func (m *MmapFileBackend) Len() uint64 {
+	return uint64(len(m.data) / EntrySize)
+}
+
+func (m *MmapFileBackend) Get(i uint64) ([4]uint64, error) {
+	if i >= m.Len() {
+		return [4]uint64{}, ErrOutOfRange
+	}
+	off := i * EntrySize
+	return decodeEntry(m.data[off : off+EntrySize]), nil
+}
+
+func (m *MmapFileBackend) Stream(from, to uint64) Iterator {
+	return &mmapIterator{backend: m, cur: from, to: to}
+}
+
+func (m *MmapFileBackend) Close() error {
+	if m.data == nil {
+		return nil
+	}
+	data := m.data
+	m.data = nil
+	return unix.Munmap(data)
+}
+
+type mmapIterator struct {
+	backend *MmapFileBackend
+	cur, to uint64
+}
+
+func (it *mmapIterator) Next() (uint64, [4]uint64, bool, error) {
+	if it.cur >= it.to || it.cur >= it.backend.Len() {
+		return 0, [4]uint64{}, false, nil
+	}
+	index := it.cur
+	value, err := it.backend.Get(index)
+	if err != nil {
+		return 0, [4]uint64{}, false, err
+	}
+	it.cur++
+	return index, value, true, nil
+}
+
+func (it *mmapIterator) Close() error {
+	return nil
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"bufio"
+	"io"
+)
+
+// ReaderAtBackend is a DBBackend over any io.ReaderAt, so a backend can be
+// built from anything with random-access reads: a local file opened with
+// O_DIRECT, an HTTP range-request client (see NewHTTPRangeBackend), or a
+// test double. n is the entry count; the backend assumes the underlying
+// source has exactly n*EntrySize bytes starting at offset 0.
+type ReaderAtBackend struct {
+	r io.ReaderAt
+	n uint64
+	c io.Closer // optional, nil if r doesn't need closing
+}
+
+// NewReaderAtBackend wraps r as a DBBackend with n entries. If r also
+// implements io.Closer, Close closes it; otherwise Close is a no-op.
+func NewReaderAtBackend(r io.ReaderAt, n uint64) *ReaderAtBackend {
+	c, _ := r.(io.Closer)
+	return &ReaderAtBackend{r: r, n: n, c: c}
+}
+
+func (b *ReaderAtBackend) Len() uint64 {
+	return b.n
+}
+
+func (b *ReaderAtBackend) Get(i uint64) ([4]uint64, error) {
+	if i >= b.n {
+		return [4]uint64{}, ErrOutOfRange
+	}
+	var buf [EntrySize]byte
+	if _, err := b.r.ReadAt(buf[:], int64(i*EntrySize)); err != nil {
+		return [4]uint64{}, err
+	}
+	return decodeEntry(buf[:]), nil
+}
+
+// streamChunkBytes caps how much of a Stream range is fetched per
+// underlying ReadAt call.
+const streamChunkBytes = 1 << 20
+
+// Stream reads [from, to) as a single contiguous section via
+// io.NewSectionReader, buffered in chunks of up to streamChunkBytes, so a
+// backend whose ReaderAt issues one underlying request per read (e.g.
+// HTTP range requests) sees one request per chunk -- a single request for
+// any range of up to streamChunkBytes -- instead of one per entry.
+func (b *ReaderAtBackend) Stream(from, to uint64) Iterator {
+	if to > b.n {
+		to = b.n
+	}
+	if from > to {
+		from = to
+	}
+	size := int64((to - from) * EntrySize)
+	section := io.NewSectionReader(b.r, int64(from*EntrySize), size)
+	bufSize := size
+	if bufSize > streamChunkBytes {
+		bufSize = streamChunkBytes
+	}
+	return &readerAtIterator{section: bufio.NewReaderSize(section, int(bufSize)), cur: from, to: to}
+}
+
+func (b *ReaderAtBackend) Close() error {
+	if b.c == nil {
+		return nil
+	}
+	return b.c.Close()
+}
+
+type readerAtIterator struct {
+	section io.Reader
+	cur, to uint64
+}
+
+func (it *readerAtIterator) Next() (uint64, [4]uint64, bool, error) {
+	if it.cur >= it.to {
+		return 0, [4]uint64{}, false, nil
+	}
+	var buf [EntrySize]byte
+	if _, err := io.ReadFull(it.section, buf[:]); err != nil {
+		return 0, [4]uint64{}, false, err
+	}
+	index := it.cur
+	it.cur++
+	return index, decodeEntry(buf[:]), true, nil
+}
+
+func (it *readerAtIterator) Close() error {
+	return nil
+}
@@ -0,0 +1,37 @@
+// Package storage abstracts where a client's database lives, so
+// Client.HintInit and Client.InitBackupHints can run against an in-memory
+// slice, an mmap'd local file, or a remote object store without a
+// separate code path for each. Backup-hint generation in particular needs
+// random access (Get), not just a forward stream, to avoid rescanning the
+// whole database once per backup hint.
+package storage
+
+// EntrySize is the on-disk/on-wire size of a single database entry: four
+// little-endian uint64 words. This matches the format produced by
+// tools/convert_db.go and pkg/dbsource.
+const EntrySize = 32
+
+// DBBackend is a random-access view of the client's database. Get should
+// be cheap enough to call once per backup-hint index (O(setSize*count)
+// total calls), and Stream should be cheap enough to call once per entry
+// in a contiguous range, so large ranges amortize any per-request
+// overhead (e.g. one HTTP Range request per PMNS bin instead of one per
+// entry).
+type DBBackend interface {
+	// Len returns the total number of entries.
+	Len() uint64
+	// Get returns the entry at index i.
+	Get(i uint64) ([4]uint64, error)
+	// Stream returns an Iterator over entries [from, to).
+	Stream(from, to uint64) Iterator
+	Close() error
+}
+
+// Iterator walks a contiguous range of entries in increasing index order.
+type Iterator interface {
+	// Next advances the iterator and reports whether an entry was
+	// available. It returns false, with no error, once the range is
+	// exhausted; a non-nil err indicates the range could not be read.
+	Next() (index uint64, value [4]uint64, ok bool, err error)
+	Close() error
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testEntries(n int) [][4]uint64 {
+	entries := make([][4]uint64, n)
+	for i := range entries {
+		entries[i] = [4]uint64{uint64(i), uint64(i) * 2, 0, 0}
+	}
+	return entries
+}
+
+func TestMemoryBackendGetBounds(t *testing.T) {
+	entries := testEntries(5)
+	m := NewMemoryBackend(entries)
+
+	if got := m.Len(); got != 5 {
+		t.Fatalf("Len() = %d, want 5", got)
+	}
+
+	for i, want := range entries {
+		got, err := m.Get(uint64(i))
+		if err != nil {
+			t.Fatalf("Get(%d): %v", i, err)
+		}
+		if got != want {
+			t.Errorf("Get(%d) = %v, want %v", i, got, want)
+		}
+	}
+
+	if _, err := m.Get(5); err != ErrOutOfRange {
+		t.Errorf("Get(5) err = %v, want ErrOutOfRange", err)
+	}
+}
+
+func TestMemoryBackendStreamRange(t *testing.T) {
+	entries := testEntries(10)
+	m := NewMemoryBackend(entries)
+
+	it := m.Stream(3, 7)
+	for i := uint64(3); i < 7; i++ {
+		index, value, ok, err := it.Next()
+		if err != nil {
+			t.Fatalf("Next(): %v", err)
+		}
+		if !ok {
+			t.Fatalf("Next() ran out early at index %d", i)
+		}
+		if index != i || value != entries[i] {
+			t.Errorf("Next() = (%d, %v), want (%d, %v)", index, value, i, entries[i])
+		}
+	}
+	if _, _, ok, _ := it.Next(); ok {
+		t.Errorf("Next() returned an entry past the end of the range")
+	}
+}
+
+// entryBytes encodes entries as the little-endian flat format decodeEntry
+// expects, the same layout ReaderAtBackend and MmapFileBackend read.
+func entryBytes(entries [][4]uint64) []byte {
+	buf := make([]byte, len(entries)*EntrySize)
+	for i, entry := range entries {
+		for w := 0; w < 4; w++ {
+			v := entry[w]
+			off := i*EntrySize + w*8
+			for b := 0; b < 8; b++ {
+				buf[off+b] = byte(v)
+				v >>= 8
+			}
+		}
+	}
+	return buf
+}
+
+func TestReaderAtBackendGetBounds(t *testing.T) {
+	entries := testEntries(5)
+	r := bytes.NewReader(entryBytes(entries))
+	b := NewReaderAtBackend(r, uint64(len(entries)))
+
+	for i, want := range entries {
+		got, err := b.Get(uint64(i))
+		if err != nil {
+			t.Fatalf("Get(%d): %v", i, err)
+		}
+		if got != want {
+			t.Errorf("Get(%d) = %v, want %v", i, got, want)
+		}
+	}
+
+	if _, err := b.Get(5); err != ErrOutOfRange {
+		t.Errorf("Get(5) err = %v, want ErrOutOfRange", err)
+	}
+}
+
+func TestReaderAtBackendStreamRange(t *testing.T) {
+	entries := testEntries(10)
+	r := bytes.NewReader(entryBytes(entries))
+	b := NewReaderAtBackend(r, uint64(len(entries)))
+
+	it := b.Stream(3, 7)
+	for i := uint64(3); i < 7; i++ {
+		index, value, ok, err := it.Next()
+		if err != nil {
+			t.Fatalf("Next(): %v", err)
+		}
+		if !ok {
+			t.Fatalf("Next() ran out early at index %d", i)
+		}
+		if index != i || value != entries[i] {
+			t.Errorf("Next() = (%d, %v), want (%d, %v)", index, value, i, entries[i])
+		}
+	}
+	if _, _, ok, _ := it.Next(); ok {
+		t.Errorf("Next() returned an entry past the end of the range")
+	}
+}
+
+func TestReaderAtBackendStreamClampsToLen(t *testing.T) {
+	entries := testEntries(4)
+	r := bytes.NewReader(entryBytes(entries))
+	b := NewReaderAtBackend(r, uint64(len(entries)))
+
+	it := b.Stream(2, 100)
+	count := 0
+	for {
+		_, _, ok, err := it.Next()
+		if err != nil {
+			t.Fatalf("Next(): %v", err)
+		}
+		if !ok {
+			break
+		}
+		count++
+	}
+	if count != 2 {
+		t.Errorf("Stream(2, 100) produced %d entries, want 2 (clamped to Len())", count)
+	}
+}
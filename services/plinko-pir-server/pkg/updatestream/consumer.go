@@ -0,0 +1,99 @@
+package updatestream
+
+import (
+	"context"
+	"log"
+
+	"github.com/Shopify/sarama"
+)
+
+// DeltaHandler is invoked for every delta consumed off the update topic.
+// It matches the signature of client.Client.UpdateHint so callers can pass
+// that method directly without this package importing the client package.
+type DeltaHandler func(index uint64, delta [4]uint64)
+
+// Consumer subscribes to the update topic as part of a consumer group and
+// dispatches received deltas to a DeltaHandler. Using a consumer group
+// means offsets are committed to Kafka, so a restart resumes from the last
+// processed message instead of replaying the whole topic.
+type Consumer struct {
+	group   sarama.ConsumerGroup
+	topic   string
+	handler DeltaHandler
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewConsumer joins cfg.Group and returns a Consumer ready to Start().
+func NewConsumer(cfg Config, handler DeltaHandler) (*Consumer, error) {
+	saramaCfg := sarama.NewConfig()
+	saramaCfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+
+	group, err := sarama.NewConsumerGroup(cfg.Brokers, cfg.Group, saramaCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Consumer{
+		group:   group,
+		topic:   cfg.Topic,
+		handler: handler,
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Start launches the background goroutine that drives the consumer group
+// session loop. sarama.ConsumerGroup.Consume returns whenever the group
+// rebalances, so it's called in a loop until the context is cancelled.
+func (c *Consumer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	go func() {
+		defer close(c.done)
+		for {
+			if err := c.group.Consume(ctx, []string{c.topic}, c); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("updatestream: consumer group session error: %v", err)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the consumer goroutine and releases the underlying group.
+func (c *Consumer) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+		<-c.done
+	}
+	return c.group.Close()
+}
+
+// Setup implements sarama.ConsumerGroupHandler.
+func (c *Consumer) Setup(sarama.ConsumerGroupSession) error { return nil }
+
+// Cleanup implements sarama.ConsumerGroupHandler.
+func (c *Consumer) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+// ConsumeClaim implements sarama.ConsumerGroupHandler. Offsets are marked
+// per-message after the handler runs so that a crash mid-batch re-delivers
+// rather than silently dropping an update.
+func (c *Consumer) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		index, delta, err := DecodeDelta(msg.Value)
+		if err != nil {
+			log.Printf("updatestream: dropping malformed message at offset %d: %v", msg.Offset, err)
+			sess.MarkMessage(msg, "")
+			continue
+		}
+		c.handler(index, delta)
+		sess.MarkMessage(msg, "")
+	}
+	return nil
+}
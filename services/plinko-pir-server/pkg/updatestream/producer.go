@@ -0,0 +1,52 @@
+package updatestream
+
+import (
+	"encoding/binary"
+
+	"github.com/Shopify/sarama"
+)
+
+// Producer publishes per-index mutations onto the update topic so that
+// subscribed clients can apply them via Client.UpdateHint.
+type Producer struct {
+	producer sarama.SyncProducer
+	topic    string
+}
+
+// NewProducer dials the given brokers and returns a Producer that publishes
+// to cfg.Topic. Messages are produced synchronously and keyed by index so
+// that updates to the same index are ordered within a partition.
+func NewProducer(cfg Config) (*Producer, error) {
+	saramaCfg := sarama.NewConfig()
+	saramaCfg.Producer.RequiredAcks = sarama.WaitForAll
+	saramaCfg.Producer.Return.Successes = true
+
+	producer, err := sarama.NewSyncProducer(cfg.Brokers, saramaCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Producer{producer: producer, topic: cfg.Topic}, nil
+}
+
+// Publish encodes (index, delta) and sends it to the update topic.
+func (p *Producer) Publish(index uint64, delta [4]uint64) error {
+	msg := &sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(indexKey(index)),
+		Value: sarama.ByteEncoder(EncodeDelta(index, delta)),
+	}
+	_, _, err := p.producer.SendMessage(msg)
+	return err
+}
+
+// Close shuts down the underlying Kafka producer.
+func (p *Producer) Close() error {
+	return p.producer.Close()
+}
+
+func indexKey(index uint64) string {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], index)
+	return string(b[:])
+}
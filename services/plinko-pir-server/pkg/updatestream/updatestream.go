@@ -0,0 +1,52 @@
+// Package updatestream publishes and consumes per-index database delta
+// messages over Kafka so that PIR clients can keep their hints fresh via
+// Client.UpdateHint without re-running the offline phase.
+//
+// Wire format for a single message value is fixed-width and little-endian:
+//
+//	bytes[0:8]   index
+//	bytes[8:40]  delta[0..3] (XOR of old and new 32-byte entry)
+package updatestream
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// messageSize is the on-wire size of a single delta message: 8 bytes for
+// the index plus 4*8 bytes for the XOR delta.
+const messageSize = 8 + 4*8
+
+// Config holds the Kafka connection parameters shared by the producer and
+// consumer sides of the update stream.
+type Config struct {
+	Brokers []string
+	Topic   string
+	// Group is only used by the consumer side; it identifies the consumer
+	// group so restarts resume from the last committed offset.
+	Group string
+}
+
+// EncodeDelta serializes an (index, delta) pair into the update stream's
+// wire format.
+func EncodeDelta(index uint64, delta [4]uint64) []byte {
+	buf := make([]byte, messageSize)
+	binary.LittleEndian.PutUint64(buf[0:8], index)
+	for i, word := range delta {
+		binary.LittleEndian.PutUint64(buf[8+i*8:16+i*8], word)
+	}
+	return buf
+}
+
+// DecodeDelta parses a message produced by EncodeDelta.
+func DecodeDelta(b []byte) (uint64, [4]uint64, error) {
+	var delta [4]uint64
+	if len(b) != messageSize {
+		return 0, delta, fmt.Errorf("updatestream: invalid message size %d, want %d", len(b), messageSize)
+	}
+	index := binary.LittleEndian.Uint64(b[0:8])
+	for i := range delta {
+		delta[i] = binary.LittleEndian.Uint64(b[8+i*8 : 16+i*8])
+	}
+	return index, delta, nil
+}
@@ -0,0 +1,33 @@
+package updatestream
+
+import "testing"
+
+func TestEncodeDecodeDeltaRoundTrip(t *testing.T) {
+	index := uint64(123456789)
+	delta := [4]uint64{1, 2, 3, 0xffffffffffffffff}
+
+	msg := EncodeDelta(index, delta)
+	if len(msg) != messageSize {
+		t.Fatalf("EncodeDelta produced %d bytes, want %d", len(msg), messageSize)
+	}
+
+	gotIndex, gotDelta, err := DecodeDelta(msg)
+	if err != nil {
+		t.Fatalf("DecodeDelta: %v", err)
+	}
+	if gotIndex != index {
+		t.Errorf("got index %d, want %d", gotIndex, index)
+	}
+	if gotDelta != delta {
+		t.Errorf("got delta %v, want %v", gotDelta, delta)
+	}
+}
+
+func TestDecodeDeltaRejectsWrongSize(t *testing.T) {
+	if _, _, err := DecodeDelta(make([]byte, messageSize-1)); err == nil {
+		t.Errorf("DecodeDelta accepted a message one byte short")
+	}
+	if _, _, err := DecodeDelta(make([]byte, messageSize+1)); err == nil {
+		t.Errorf("DecodeDelta accepted a message one byte too long")
+	}
+}
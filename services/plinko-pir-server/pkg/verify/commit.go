@@ -0,0 +1,92 @@
+package verify
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"plinko-pir-server/pkg/storage"
+)
+
+// Commitment holds the server's secret commitment state for the lifetime
+// of a committed database: the per-entry blinding factors (needed to open
+// a subset) and the BLS signing key (needed to attest openings), alongside
+// the public VerifierParams handed out to clients.
+type Commitment struct {
+	Params VerifierParams
+	basis  *basis
+
+	blinding []*bls12381.Fr
+	signKey  *bls12381.Fr
+}
+
+// Commit builds a per-entry Pedersen commitment D_i = Sum_w(v_i[w]*G_w) +
+// r_i*H for every entry, plus a fresh BLS keypair for attesting openings.
+// It reads db through storage.DBBackend rather than taking a materialized
+// [][4]uint64, so committing the live database doesn't require buffering
+// it a second time in Go memory (see newServerFromSource's doc comment).
+// Commit must be called once at setup time; the returned Commitment must
+// be kept by the server for as long as the database is served, since
+// Open needs the blinding factors and signing key to produce proofs.
+func Commit(db storage.DBBackend) (*Commitment, error) {
+	b := newBasis()
+	g1 := b.g1
+
+	n := db.Len()
+	blinding := make([]*bls12381.Fr, n)
+	commitments := make([]*bls12381.PointG1, n)
+	total := g1.Zero()
+
+	for i := uint64(0); i < n; i++ {
+		entry, err := db.Get(i)
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := bls12381.NewFr().Rand(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		blinding[i] = r
+
+		d := g1.MulScalar(g1.New(), b.h, r)
+		for w := 0; w < 4; w++ {
+			scalar := bls12381.NewFr().FromBytes(uint64ToBytes(entry[w]))
+			term := g1.MulScalar(g1.New(), b.gw[w], scalar)
+			g1.Add(d, d, term)
+		}
+		commitments[i] = d
+		g1.Add(total, total, d)
+	}
+
+	signKey, err := bls12381.NewFr().Rand(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	signPub := b.g2.MulScalar(b.g2.New(), b.g2.One(), signKey)
+
+	return &Commitment{
+		Params: VerifierParams{
+			EntryCommitments: commitments,
+			Commitment:       total,
+			BasisDigest:      b.digest(),
+			SigningKey:       signPub,
+		},
+		basis:    b,
+		blinding: blinding,
+		signKey:  signKey,
+	}, nil
+}
+
+// uint64ToBytes encodes v as the big-endian bytes Fr.FromBytes expects
+// (it treats its input as a big.Int, which is big-endian), so the Fr
+// scalar it produces equals v exactly -- unlike putUint64's little-endian
+// convention, which Fr.FromBytes would read as byteswap64(v) and which
+// breaks the additive homomorphism SumParity/RecoverPunctured rely on for
+// subsets of more than one entry.
+func uint64ToBytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
@@ -0,0 +1,95 @@
+package verify
+
+import (
+	"encoding/binary"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"plinko-pir-server/pkg/storage"
+)
+
+// FieldParity is a per-word BLS12-381 scalar field element, stored as its
+// canonical big-endian encoding. It's the field-additive analogue of a
+// plain Plinko hint's [4]uint64 parity, but unlike that type it can't be
+// narrowed back to a uint64: SumParity accumulates up to len(indices)
+// terms, and the running sum routinely exceeds 2^64 for any subset of
+// more than a handful of entries, well before it has any chance of
+// wrapping the ~255-bit field order. Only the *difference* of two such
+// sums over sets differing by one index (see RecoverPunctured) is
+// guaranteed to collapse back to a single entry's own word, which is why
+// that -- and only that -- conversion goes back through uint64FromField.
+type FieldParity [4][32]byte
+
+// SumParity computes the field-additive parity of db's entries at
+// indices: the entry-wise sum mod the BLS12-381 scalar field, using the
+// same per-word encoding Commit and Verify use. Plain Plinko hints
+// accumulate via XOR (see client/offline.go) since Plinko's O(1) updates
+// depend on GF(2) algebra, but a Pedersen commitment is only additively
+// homomorphic, so a verified-mode hint's parity -- and the server's
+// answer to a verified query -- must be built this way instead for
+// Client.ReconstructVerified's combination step to recover the right
+// value.
+func SumParity(db storage.DBBackend, indices []uint64) (FieldParity, error) {
+	var sum [4]*bls12381.Fr
+	for w := range sum {
+		sum[w] = bls12381.NewFr()
+	}
+	for _, i := range indices {
+		entry, err := db.Get(i)
+		if err != nil {
+			return FieldParity{}, err
+		}
+		for w := 0; w < 4; w++ {
+			term := bls12381.NewFr().FromBytes(uint64ToBytes(entry[w]))
+			sum[w].Add(sum[w], term)
+		}
+	}
+
+	var out FieldParity
+	for w := 0; w < 4; w++ {
+		out[w] = fieldWordFromFr(sum[w])
+	}
+	return out, nil
+}
+
+// RecoverPunctured inverts SumParity's accumulation: given a hint's
+// full-set parity and the server's parity over that set with the target
+// punctured out, it returns the target's own value. Field addition isn't
+// its own inverse the way XOR is, so this is a subtraction (full -
+// punctured), not another sum. Unlike its FieldParity inputs, the result
+// is safe to narrow to [4]uint64: full and punctured differ by exactly
+// one database entry, and every entry word is itself a uint64.
+func RecoverPunctured(fullParity, puncturedParity FieldParity) [4]uint64 {
+	var out [4]uint64
+	for w := 0; w < 4; w++ {
+		full := frFromFieldWord(fullParity[w])
+		punctured := frFromFieldWord(puncturedParity[w])
+		diff := bls12381.NewFr()
+		diff.Sub(full, punctured)
+		out[w] = uint64FromField(diff)
+	}
+	return out
+}
+
+// fieldWordFromFr encodes fr as the canonical big-endian FieldParity word
+// Fr.ToBytes produces.
+func fieldWordFromFr(fr *bls12381.Fr) [32]byte {
+	var out [32]byte
+	copy(out[:], fr.ToBytes())
+	return out
+}
+
+// frFromFieldWord inverts fieldWordFromFr.
+func frFromFieldWord(word [32]byte) *bls12381.Fr {
+	return bls12381.NewFr().FromBytes(word[:])
+}
+
+// uint64FromField reads a field element's low-order bytes back out of its
+// canonical big-endian encoding. Only exact when the element's value
+// actually fits in 64 bits -- true for a single entry word, and for
+// RecoverPunctured's difference of two FieldParity sums, but not for a
+// FieldParity sum itself (see its doc comment).
+func uint64FromField(fr *bls12381.Fr) uint64 {
+	b := fr.ToBytes()
+	return binary.BigEndian.Uint64(b[len(b)-8:])
+}
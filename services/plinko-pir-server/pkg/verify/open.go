@@ -0,0 +1,100 @@
+package verify
+
+import (
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// Opening is the proof a server attaches to a query response so the
+// client can check the returned parity against the committed database
+// instead of trusting it blindly. R lets the client turn the public
+// per-entry commitments for the queried subset into a single Pedersen
+// commitment to parity; Sig is a BLS signature over a Fiat-Shamir
+// challenge derived from (Commitment, subset, parity), binding the
+// opening to this exact query so it can't be replayed against another.
+type Opening struct {
+	R   *bls12381.Fr
+	Sig *bls12381.PointG1
+}
+
+// Open proves that parity is the sum of the entries at subset (subset
+// must be sorted and within range, and parity must combine shares via
+// field addition rather than XOR — see SumParity). It's a method on
+// Commitment because producing it needs the per-entry blinding factors
+// and the signing key, both of which Commit keeps secret from clients.
+func (c *Commitment) Open(subset []uint64, parity FieldParity) *Opening {
+	r := bls12381.NewFr()
+	for _, i := range subset {
+		r.Add(r, c.blinding[i])
+	}
+
+	challenge := openChallenge(c.Params.Commitment, c.basis.g1, subset, parity)
+	sigPoint := hashToG1(c.basis.g1, challenge[:])
+	sig := c.basis.g1.MulScalar(c.basis.g1.New(), sigPoint, c.signKey)
+
+	return &Opening{R: r, Sig: sig}
+}
+
+// Verify recomputes the subset's aggregate commitment from
+// params.EntryCommitments (no server help needed for this part, since the
+// homomorphism Sum(D_i) = (Sum v_i)*G + (Sum r_i)*H holds over the shared
+// basis), checks it opens to parity under proof.R, then checks proof.Sig
+// is a valid BLS signature over the matching Fiat-Shamir challenge. Both
+// checks must hold for the opening to be accepted.
+func Verify(params VerifierParams, subset []uint64, parity FieldParity, proof *Opening) bool {
+	b := newBasis()
+	g1 := b.g1
+
+	if b.digest() != params.BasisDigest {
+		return false
+	}
+
+	sum := g1.Zero()
+	for _, i := range subset {
+		if i >= uint64(len(params.EntryCommitments)) {
+			return false
+		}
+		g1.Add(sum, sum, params.EntryCommitments[i])
+	}
+
+	expected := g1.MulScalar(g1.New(), b.h, proof.R)
+	for w := 0; w < 4; w++ {
+		scalar := frFromFieldWord(parity[w])
+		term := g1.MulScalar(g1.New(), b.gw[w], scalar)
+		g1.Add(expected, expected, term)
+	}
+	if !g1.Equal(sum, expected) {
+		return false
+	}
+
+	challenge := openChallenge(params.Commitment, g1, subset, parity)
+	sigPoint := hashToG1(g1, challenge[:])
+
+	engine := bls12381.NewEngine()
+	engine.AddPair(proof.Sig, b.g2.One())
+	engine.AddPairInv(sigPoint, params.SigningKey)
+	return engine.Check()
+}
+
+func openChallenge(commitment *bls12381.PointG1, g1 *bls12381.G1, subset []uint64, parity FieldParity) [32]byte {
+	tr := NewTranscript("plinko-verify/open")
+	tr.Append("commitment", g1.ToBytes(commitment))
+	tr.Append("subset", encodeSubset(subset))
+	tr.Append("parity", encodeParity(parity))
+	return tr.Challenge("open")
+}
+
+func encodeSubset(subset []uint64) []byte {
+	out := make([]byte, 8*len(subset))
+	for k, i := range subset {
+		putUint64(out[8*k:8*k+8], i)
+	}
+	return out
+}
+
+func encodeParity(parity FieldParity) []byte {
+	out := make([]byte, 0, 4*32)
+	for _, word := range parity {
+		out = append(out, word[:]...)
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+// Package verify lets a Plinko PIR client detect a cheating server without
+// re-downloading the database. Each entry is committed individually as a
+// Pedersen commitment D_i = v_i*G + r_i*H over BLS12-381; because every
+// D_i shares the same (G, H) basis, the homomorphism Sum(D_i) = (Sum
+// v_i)*G + (Sum r_i)*H lets a client recompute the commitment to any
+// subset's parity on its own from the public D_i array, then check it
+// against a server-supplied opening. The opening is additionally bound to
+// the query via a BLS signature over a Fiat-Shamir challenge derived from
+// H(C || subset || parity), so a pairing check catches a server that
+// tries to reuse an opening computed for a different query.
+//
+// This trades the bandwidth of a single succinct vector commitment for
+// simplicity: publishing one G1 point per entry is O(n) group elements,
+// not O(1), but avoids needing a polynomial-commitment batch-opening
+// protocol (KZG/Bulletproofs) to prove an arbitrary-subset sum.
+package verify
+
+import (
+	"fmt"
+
+	bls12381 "github.com/kilic/bls12-381"
+	"golang.org/x/crypto/sha3"
+)
+
+// VerifierParams is everything a client needs to verify query openings:
+// the per-entry commitments (so it can recompute a subset's aggregate
+// commitment itself), the whole-database digest, the basis digest the
+// commitments were computed against, and the server's BLS public key for
+// checking opening attestations. It's its own package-level struct (not a
+// method on client.Client) so backup hints, which are verified the same
+// way as primary hints, can share one instance.
+type VerifierParams struct {
+	// EntryCommitments[i] = v_i*G + r_i*H, published once at setup time.
+	EntryCommitments []*bls12381.PointG1
+	// Commitment is the whole-database digest, Sum(EntryCommitments).
+	Commitment *bls12381.PointG1
+	// BasisDigest identifies (G, H) so a client can confirm it's
+	// verifying against the basis the server claims.
+	BasisDigest [32]byte
+	// SigningKey is the server's BLS public key (sk*G2gen) used to
+	// attest openings.
+	SigningKey *bls12381.PointG2
+}
+
+// basis holds the shared Pedersen generators: one G per DB-entry word
+// (entries are [4]uint64) plus the blinding generator H. Unlike a
+// per-index vector-commitment basis, every entry commitment uses the same
+// four word generators, which is what makes per-subset sums recomputable
+// by a client that only knows the public EntryCommitments array.
+type basis struct {
+	g1 *bls12381.G1
+	g2 *bls12381.G2
+	gw [4]*bls12381.PointG1
+	h  *bls12381.PointG1
+}
+
+// newBasis derives the word generators and H deterministically from fixed
+// domain-separated seeds, so client and server always agree on the basis
+// without either side transmitting it.
+func newBasis() *basis {
+	g1 := bls12381.NewG1()
+	g2 := bls12381.NewG2()
+	b := &basis{g1: g1, g2: g2, h: hashToG1(g1, domainSeed("plinko-verify/H"))}
+	for w := 0; w < 4; w++ {
+		b.gw[w] = hashToG1(g1, domainSeed(fmt.Sprintf("plinko-verify/G/%d", w)))
+	}
+	return b
+}
+
+func hashToG1(g1 *bls12381.G1, seed []byte) *bls12381.PointG1 {
+	p, err := g1.HashToCurve(seed, []byte("plinko-verify-g1-dst"))
+	if err != nil {
+		// HashToCurve only fails on a malformed DST, which is a
+		// constant here, so this is unreachable in practice.
+		panic(err)
+	}
+	return p
+}
+
+func domainSeed(label string) []byte {
+	h := sha3.NewShake256()
+	_, _ = h.Write([]byte(label))
+	seed := make([]byte, 32)
+	_, _ = h.Read(seed)
+	return seed
+}
+
+// digest hashes the word generators and H into the value published as
+// VerifierParams.BasisDigest.
+func (b *basis) digest() [32]byte {
+	h := sha3.NewShake256()
+	for _, g := range b.gw {
+		_, _ = h.Write(b.g1.ToBytes(g))
+	}
+	_, _ = h.Write(b.g1.ToBytes(b.h))
+	var out [32]byte
+	_, _ = h.Read(out[:])
+	return out
+}
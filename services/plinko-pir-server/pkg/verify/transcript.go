@@ -0,0 +1,59 @@
+package verify
+
+import "golang.org/x/crypto/sha3"
+
+// Transcript implements a domain-separated Fiat-Shamir hash chain over
+// SHAKE256 (the Keccak-based extendable-output function, not the
+// fixed-output Keccak-256), read out to 32 bytes per challenge. Every
+// Append call folds its label and data into the running
+// state before the next Challenge is derived, so nested challenges (e.g.
+// one per queried index in a batched opening) can't be replayed or
+// reordered without changing the final digest.
+type Transcript struct {
+	state sha3.ShakeHash
+}
+
+// NewTranscript starts a transcript domain-separated by label, so
+// transcripts for unrelated protocols (or protocol versions) never collide
+// even if fed the same messages.
+func NewTranscript(label string) *Transcript {
+	t := &Transcript{state: sha3.NewShake256()}
+	t.appendRaw("domain", []byte(label))
+	return t
+}
+
+// Append folds label and data into the transcript.
+func (t *Transcript) Append(label string, data []byte) {
+	t.appendRaw(label, data)
+}
+
+func (t *Transcript) appendRaw(label string, data []byte) {
+	_, _ = t.state.Write([]byte(label))
+	var lenPrefix [8]byte
+	putUint64(lenPrefix[:], uint64(len(data)))
+	_, _ = t.state.Write(lenPrefix[:])
+	_, _ = t.state.Write(data)
+}
+
+// Challenge derives a 32-byte challenge from the transcript so far, then
+// folds the challenge itself back in so a subsequent Challenge call (e.g.
+// for the next queried index in a batch) can't be derived independently of
+// this one.
+func (t *Transcript) Challenge(label string) [32]byte {
+	t.appendRaw("challenge", []byte(label))
+
+	// Clone the running state so deriving this challenge doesn't consume
+	// bytes that a later Append/Challenge call needs to see.
+	clone := t.state.Clone()
+	var out [32]byte
+	_, _ = clone.Read(out[:])
+
+	t.appendRaw("challenge-out", out[:])
+	return out
+}
+
+func putUint64(b []byte, v uint64) {
+	for i := 0; i < 8; i++ {
+		b[i] = byte(v >> (8 * i))
+	}
+}
@@ -0,0 +1,119 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// MarshalJSON encodes a FieldParity as the base64 of its four 32-byte
+// words concatenated, so it travels over JSON as a single string instead
+// of as 128 bare numbers.
+func (p FieldParity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodeParity(p))
+}
+
+// UnmarshalJSON inverts MarshalJSON.
+func (p *FieldParity) UnmarshalJSON(data []byte) error {
+	var b []byte
+	if err := json.Unmarshal(data, &b); err != nil {
+		return err
+	}
+	if len(b) != 4*32 {
+		return fmt.Errorf("verify: field parity must be 128 bytes, got %d", len(b))
+	}
+	for w := 0; w < 4; w++ {
+		copy(p[w][:], b[w*32:(w+1)*32])
+	}
+	return nil
+}
+
+// EncodeOpening serializes proof as R's canonical Fr encoding followed by
+// Sig's compressed G1 encoding, so a server can hand a proof to a client
+// over HTTP without bls12-381's field/point types needing to implement
+// json.Marshaler themselves.
+func EncodeOpening(proof *Opening) []byte {
+	g1 := bls12381.NewG1()
+	out := append([]byte{}, proof.R.ToBytes()...)
+	return append(out, g1.ToCompressed(proof.Sig)...)
+}
+
+// DecodeOpening inverts EncodeOpening.
+func DecodeOpening(b []byte) (*Opening, error) {
+	frSize := len(bls12381.NewFr().ToBytes())
+	if len(b) <= frSize {
+		return nil, fmt.Errorf("verify: opening must be more than %d bytes, got %d", frSize, len(b))
+	}
+
+	g1 := bls12381.NewG1()
+	sig, err := g1.FromCompressed(b[frSize:])
+	if err != nil {
+		return nil, fmt.Errorf("verify: decoding opening signature: %w", err)
+	}
+	return &Opening{R: bls12381.NewFr().FromBytes(b[:frSize]), Sig: sig}, nil
+}
+
+// EncodeParams serializes params as: BasisDigest, the compressed
+// SigningKey (G2), the compressed Commitment (G1), then one compressed G1
+// per EntryCommitments entry, in order. The EntryCommitments section
+// dominates size -- O(n) group elements, one per database entry -- which
+// is the bandwidth this scheme trades away in exchange for not needing a
+// polynomial-commitment batch-opening protocol (see the package doc).
+func EncodeParams(params VerifierParams) []byte {
+	g1 := bls12381.NewG1()
+	g2 := bls12381.NewG2()
+
+	out := make([]byte, 0, 32+96+48+48*len(params.EntryCommitments))
+	out = append(out, params.BasisDigest[:]...)
+	out = append(out, g2.ToCompressed(params.SigningKey)...)
+	out = append(out, g1.ToCompressed(params.Commitment)...)
+	for _, c := range params.EntryCommitments {
+		out = append(out, g1.ToCompressed(c)...)
+	}
+	return out
+}
+
+// DecodeParams inverts EncodeParams.
+func DecodeParams(b []byte) (VerifierParams, error) {
+	g1 := bls12381.NewG1()
+	g2 := bls12381.NewG2()
+
+	g1Size := len(g1.ToCompressed(g1.One()))
+	g2Size := len(g2.ToCompressed(g2.One()))
+
+	if len(b) < 32+g2Size+g1Size {
+		return VerifierParams{}, fmt.Errorf("verify: params too short: %d bytes", len(b))
+	}
+
+	var params VerifierParams
+	copy(params.BasisDigest[:], b[:32])
+	b = b[32:]
+
+	signingKey, err := g2.FromCompressed(b[:g2Size])
+	if err != nil {
+		return VerifierParams{}, fmt.Errorf("verify: decoding signing key: %w", err)
+	}
+	params.SigningKey = signingKey
+	b = b[g2Size:]
+
+	commitment, err := g1.FromCompressed(b[:g1Size])
+	if err != nil {
+		return VerifierParams{}, fmt.Errorf("verify: decoding commitment: %w", err)
+	}
+	params.Commitment = commitment
+	b = b[g1Size:]
+
+	if len(b)%g1Size != 0 {
+		return VerifierParams{}, fmt.Errorf("verify: entry commitments section isn't a multiple of %d bytes", g1Size)
+	}
+	params.EntryCommitments = make([]*bls12381.PointG1, len(b)/g1Size)
+	for i := range params.EntryCommitments {
+		c, err := g1.FromCompressed(b[i*g1Size : (i+1)*g1Size])
+		if err != nil {
+			return VerifierParams{}, fmt.Errorf("verify: decoding entry commitment %d: %w", i, err)
+		}
+		params.EntryCommitments[i] = c
+	}
+	return params, nil
+}
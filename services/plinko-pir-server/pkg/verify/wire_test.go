@@ -0,0 +1,102 @@
+package verify
+
+import (
+	"encoding/json"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+
+	"plinko-pir-server/pkg/storage"
+)
+
+func testCommitment(t *testing.T, n int) (*Commitment, storage.DBBackend) {
+	t.Helper()
+	db := make([][4]uint64, n)
+	for i := range db {
+		db[i] = [4]uint64{uint64(i) * 7, uint64(i) + 100, 0, 0}
+	}
+	backend := storage.NewMemoryBackend(db)
+
+	commitment, err := Commit(backend)
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	return commitment, backend
+}
+
+func TestFieldParityJSONRoundTrip(t *testing.T) {
+	_, backend := testCommitment(t, 4)
+
+	parity, err := SumParity(backend, []uint64{0, 1, 2})
+	if err != nil {
+		t.Fatalf("SumParity: %v", err)
+	}
+
+	data, err := json.Marshal(parity)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got FieldParity
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got != parity {
+		t.Errorf("round trip = %v, want %v", got, parity)
+	}
+}
+
+func TestEncodeDecodeOpeningRoundTrip(t *testing.T) {
+	commitment, backend := testCommitment(t, 6)
+
+	subset := []uint64{1, 2, 4}
+	parity, err := SumParity(backend, subset)
+	if err != nil {
+		t.Fatalf("SumParity: %v", err)
+	}
+	proof := commitment.Open(subset, parity)
+
+	decoded, err := DecodeOpening(EncodeOpening(proof))
+	if err != nil {
+		t.Fatalf("DecodeOpening: %v", err)
+	}
+
+	if !Verify(commitment.Params, subset, parity, decoded) {
+		t.Errorf("Verify rejected an opening round-tripped through EncodeOpening/DecodeOpening")
+	}
+}
+
+func TestEncodeDecodeParamsRoundTrip(t *testing.T) {
+	commitment, _ := testCommitment(t, 5)
+
+	decoded, err := DecodeParams(EncodeParams(commitment.Params))
+	if err != nil {
+		t.Fatalf("DecodeParams: %v", err)
+	}
+
+	if decoded.BasisDigest != commitment.Params.BasisDigest {
+		t.Errorf("BasisDigest mismatch")
+	}
+	if len(decoded.EntryCommitments) != len(commitment.Params.EntryCommitments) {
+		t.Fatalf("got %d entry commitments, want %d", len(decoded.EntryCommitments), len(commitment.Params.EntryCommitments))
+	}
+
+	g1 := bls12381.NewG1()
+	if !g1.Equal(decoded.Commitment, commitment.Params.Commitment) {
+		t.Errorf("Commitment point mismatch after round trip")
+	}
+	for i := range decoded.EntryCommitments {
+		if !g1.Equal(decoded.EntryCommitments[i], commitment.Params.EntryCommitments[i]) {
+			t.Errorf("EntryCommitments[%d] mismatch after round trip", i)
+		}
+	}
+}
+
+func TestDecodeParamsRejectsTruncatedInput(t *testing.T) {
+	commitment, _ := testCommitment(t, 3)
+
+	encoded := EncodeParams(commitment.Params)
+	if _, err := DecodeParams(encoded[:len(encoded)-1]); err == nil {
+		t.Errorf("DecodeParams accepted an input one byte short")
+	}
+}
@@ -1,16 +1,94 @@
 package main
 
 import (
-	"encoding/binary"
+	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
+	"math"
 	"math/big"
 	"net/http"
-	"os"
+	"runtime"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/bits-and-blooms/bitset"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"plinko-pir-server/pkg/batchserver"
+	"plinko-pir-server/pkg/dbsource"
+	"plinko-pir-server/pkg/storage"
+	"plinko-pir-server/pkg/updatestream"
+	"plinko-pir-server/pkg/verify"
+)
+
+var (
+	dbPath     = flag.String("db", "", "Path to a flat file of 32-byte entries; ignored if -pg-conn is set")
+	listenAddr = flag.String("addr", ":8080", "HTTP listen address")
+
+	updateStreamBrokers = flag.String("update-brokers", "", "Comma-separated Kafka broker list for the update stream (empty disables it)")
+	updateStreamTopic   = flag.String("update-topic", "plinko-db-updates", "Kafka topic to publish database deltas to")
+	updateStreamGroup   = flag.String("update-group", "plinko-pir-server", "Kafka consumer group ID (unused on the producer side, kept for symmetry with the client flag set)")
+
+	pgConnString = flag.String("pg-conn", "", "Postgres connection string for the entries table; when set, -db is ignored")
+	pgTable      = flag.String("pg-table", "entries", "Postgres table holding 32-byte entries (idx, value columns)")
+	pgNotifyChan = flag.String("pg-notify-channel", "plinko_entry_updates", "Postgres NOTIFY channel carrying entry deltas")
+
+	maxBatchQueries = flag.Int("max-batch", 64, "Maximum number of queries accepted by a single POST /plinko/batch request")
+
+	adminSecret = flag.String("admin-secret", "", "Shared secret required in the X-Admin-Secret header for POST /update; empty refuses every request, since /update is admin-only and must not be left open")
+
+	enableVerify = flag.Bool("verify", false, "Commit the database at startup and serve POST /plinko/verify and GET /verify/params, so clients can detect a cheating server; adds an O(n) group-exponentiation pass at load time and is incompatible with a live /update stream (committed entries silently stop matching their proofs once mutated)")
 )
 
+// main loads the database (from -db or -pg-conn), wires the HTTP handlers
+// up to a ServeMux, and serves on -addr until the process is killed.
+func main() {
+	flag.Parse()
+
+	var server *PlinkoPIRServer
+	if *pgConnString != "" {
+		server = loadServerFromPostgres(context.Background())
+	} else {
+		if *dbPath == "" {
+			log.Fatal("either -db or -pg-conn must be set")
+		}
+		server = loadServer(*dbPath)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", server.healthHandler)
+	mux.HandleFunc("/query", corsMiddleware(server.plaintextQueryHandler))
+	mux.HandleFunc("/plinko", corsMiddleware(server.plinkoQueryHandler))
+	mux.HandleFunc("/plinko/batch", corsMiddleware(server.plinkoBatchHandler))
+	mux.HandleFunc("/plinko/batch/csr", corsMiddleware(server.csrBatchHandler))
+	mux.HandleFunc("/plinko/verify", corsMiddleware(server.plinkoVerifiedQueryHandler))
+	mux.HandleFunc("/verify/params", corsMiddleware(server.verifyParamsHandler))
+	mux.HandleFunc("/update", server.updateHandler)
+	mux.Handle("/metrics", server.metricsHandler())
+
+	log.Printf("plinko-pir-server listening on %s (db_size=%d chunk_size=%d set_size=%d)", *listenAddr, server.dbSize, server.chunkSize, server.setSize)
+	log.Fatal(http.ListenAndServe(*listenAddr, mux))
+}
+
+// derivePlinkoParams picks a (chunkSize, setSize) pair covering at least
+// entryCount entries, with setSize close to sqrt(entryCount) so the
+// client's O(setSize) hint count and O(chunkSize) per-hint download stay
+// balanced. chunkSize*setSize may overshoot entryCount slightly when
+// entryCount isn't a perfect square; the padding entries read as zero.
+func derivePlinkoParams(entryCount uint64) (chunkSize, setSize uint64) {
+	setSize = uint64(math.Ceil(math.Sqrt(float64(entryCount))))
+	if setSize == 0 {
+		setSize = 1
+	}
+	chunkSize = (entryCount + setSize - 1) / setSize
+	return chunkSize, setSize
+}
+
 const (
 	DBEntrySize   = 32
 	DBEntryLength = 4
@@ -23,6 +101,75 @@ type PlinkoPIRServer struct {
 	dbSize    uint64
 	chunkSize uint64
 	setSize   uint64
+
+	dbMu         sync.RWMutex
+	updateStream *updatestream.Producer
+
+	// commitment is non-nil only when the server was started with
+	// -verify. It's set once at load time and never mutated afterward,
+	// so reading it from a handler goroutine needs no locking of its own.
+	commitment *verify.Commitment
+
+	pSetPool sync.Pool
+}
+
+// acquirePSet returns a *bitset.BitSet sized to s.setSize, reusing a
+// previously returned one when available so HandlePlinkoQuery doesn't
+// allocate on every request.
+func (s *PlinkoPIRServer) acquirePSet() *bitset.BitSet {
+	if v := s.pSetPool.Get(); v != nil {
+		return v.(*bitset.BitSet)
+	}
+	return bitset.New(uint(s.setSize))
+}
+
+// releasePSet clears b and returns it to the pool for reuse.
+func (s *PlinkoPIRServer) releasePSet(b *bitset.BitSet) {
+	b.ClearAll()
+	s.pSetPool.Put(b)
+}
+
+// UpdateRequest describes a single-index mutation to apply to the in-memory
+// database. Delta is old XOR new, matching the format Client.UpdateHint
+// expects.
+type UpdateRequest struct {
+	Index uint64    `json:"index"`
+	Delta [4]uint64 `json:"delta"`
+}
+
+// newUpdateStreamProducer dials the Kafka brokers configured via flags. It
+// returns nil if no brokers are configured, in which case /update still
+// applies the mutation locally but does not publish it.
+func newUpdateStreamProducer() *updatestream.Producer {
+	if *updateStreamBrokers == "" {
+		return nil
+	}
+
+	producer, err := updatestream.NewProducer(updatestream.Config{
+		Brokers: strings.Split(*updateStreamBrokers, ","),
+		Topic:   *updateStreamTopic,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to Kafka brokers %s: %v", *updateStreamBrokers, err)
+	}
+	return producer
+}
+
+// applyDeltaLocal atomically XORs delta into the in-memory database at
+// index. It's the single code path shared by the /update HTTP handler and
+// the Postgres LISTEN/NOTIFY listener, so both transports keep the
+// database consistent with Client.UpdateHint the same way.
+func (s *PlinkoPIRServer) applyDeltaLocal(index uint64, delta [4]uint64) {
+	if index >= uint64(len(s.database)/DBEntryLength) {
+		return
+	}
+
+	s.dbMu.Lock()
+	base := index * DBEntryLength
+	for k := 0; k < DBEntryLength; k++ {
+		s.database[base+uint64(k)] ^= delta[k]
+	}
+	s.dbMu.Unlock()
 }
 
 type PlaintextQueryRequest struct {
@@ -65,46 +212,136 @@ func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// adminAuthorized checks r's X-Admin-Secret header against -admin-secret
+// in constant time, so admin-only handlers like updateHandler can't be
+// driven by an unauthenticated caller. An empty -admin-secret refuses
+// every request rather than allowing them through, since the endpoints
+// it guards are explicitly admin-only.
+func adminAuthorized(r *http.Request) bool {
+	if *adminSecret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Secret")), []byte(*adminSecret)) == 1
+}
+
+// loadServer reads the database from a flat file on disk. It's a thin
+// wrapper around newServerFromSource for the common case of a local file;
+// loadServerFromPostgres is the equivalent entry point for a Postgres-backed
+// deployment.
 func loadServer(databasePath string) *PlinkoPIRServer {
-	data, err := os.ReadFile(databasePath)
+	src, err := dbsource.NewFileSource(databasePath)
 	if err != nil {
 		log.Fatalf("Failed to read database file %s: %v", databasePath, err)
 	}
+	defer src.Close()
+
+	server, err := newServerFromSource(context.Background(), src)
+	if err != nil {
+		log.Fatalf("Failed to load database: %v", err)
+	}
+	return server
+}
+
+// loadServerFromPostgres builds a server backed by a Postgres table and
+// starts a background LISTEN/NOTIFY goroutine so row changes feed into the
+// same delta path used by the Kafka update stream.
+func loadServerFromPostgres(ctx context.Context) *PlinkoPIRServer {
+	src, err := dbsource.NewPostgresSource(ctx, *pgConnString, *pgTable)
+	if err != nil {
+		log.Fatalf("Failed to connect to Postgres: %v", err)
+	}
 
-	if len(data)%DBEntrySize != 0 {
-		log.Fatalf("Invalid database file: size %d is not a multiple of %d", len(data), DBEntrySize)
+	server, err := newServerFromSource(ctx, src)
+	if err != nil {
+		log.Fatalf("Failed to load database from Postgres: %v", err)
 	}
 
-	entryCount := len(data) / DBEntrySize
+	// Row changes are applied locally and, like /update, published to the
+	// Kafka update stream when one is configured, so subscribed clients'
+	// Client.UpdateHint sees them too.
+	onDelta := func(index uint64, delta [4]uint64) {
+		if index >= server.dbSize {
+			return
+		}
+		server.applyDeltaLocal(index, delta)
+		if server.updateStream != nil {
+			if err := server.updateStream.Publish(index, delta); err != nil {
+				log.Printf("Failed to publish Postgres update for index %d: %v", index, err)
+			}
+		}
+	}
+
+	go func() {
+		if err := src.ListenForUpdates(ctx, *pgNotifyChan, onDelta); err != nil {
+			log.Printf("Postgres update listener stopped: %v", err)
+		}
+	}()
+
+	return server
+}
+
+// newServerFromSource streams entries out of src and decodes them directly
+// into the flat []uint64 database, so the database is never buffered twice
+// in Go memory regardless of which Source backs it.
+func newServerFromSource(ctx context.Context, src dbsource.Source) (*PlinkoPIRServer, error) {
+	entryCount, err := src.Len(ctx)
+	if err != nil {
+		return nil, err
+	}
 	if entryCount == 0 {
-		log.Fatal("Invalid database file: contains zero entries")
+		return nil, fmt.Errorf("database contains zero entries")
 	}
 
-	dbSize := uint64(entryCount)
-	chunkSize, setSize := derivePlinkoParams(dbSize)
+	chunkSize, setSize := derivePlinkoParams(entryCount)
 	totalEntries := chunkSize * setSize
 
 	// database slice holds flattened uint64 words
 	database := make([]uint64, totalEntries*DBEntryLength)
 
-	for i := 0; i < entryCount; i++ {
-		for j := 0; j < DBEntryLength; j++ {
-			offset := i*DBEntrySize + j*8
-			if offset+8 <= len(data) {
-				database[i*DBEntryLength+j] = binary.LittleEndian.Uint64(data[offset : offset+8])
-			}
+	err = src.Stream(ctx, func(entry dbsource.Entry) error {
+		if entry.Index >= entryCount {
+			return fmt.Errorf("entry index %d out of range for db size %d", entry.Index, entryCount)
+		}
+		base := entry.Index * DBEntryLength
+		for k := 0; k < DBEntryLength; k++ {
+			database[base+uint64(k)] = entry.Value[k]
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return &PlinkoPIRServer{
-		database:  database,
-		dbSize:    dbSize,
-		chunkSize: chunkSize,
-		setSize:   setSize,
+	server := &PlinkoPIRServer{
+		database:     database,
+		dbSize:       entryCount,
+		chunkSize:    chunkSize,
+		setSize:      setSize,
+		updateStream: newUpdateStreamProducer(),
 	}
+	server.recordServerParams()
+
+	if *enableVerify {
+		commitment, err := verify.Commit(dbBackend{s: server})
+		if err != nil {
+			return nil, fmt.Errorf("committing database for -verify: %w", err)
+		}
+		server.commitment = commitment
+	}
+
+	return server, nil
 }
 
+// DBAccess reads a single entry out of the in-memory database. It takes
+// dbMu's read lock for the duration of the read -- applyDeltaLocal takes
+// the write lock while XORing a delta in, so a concurrent update can't be
+// observed half-applied, but concurrent reads (the common case: up to
+// setSize per query, across every worker HandlePlinkoQueryParallel
+// spawns) don't serialize against each other.
 func (s *PlinkoPIRServer) DBAccess(id uint64) DBEntry {
+	s.dbMu.RLock()
+	defer s.dbMu.RUnlock()
+
 	if id < uint64(len(s.database)/DBEntryLength) {
 		startIdx := id * DBEntryLength
 		var entry DBEntry
@@ -116,6 +353,56 @@ func (s *PlinkoPIRServer) DBAccess(id uint64) DBEntry {
 	return DBEntry{}
 }
 
+// dbBackend adapts PlinkoPIRServer's in-memory database to storage.DBBackend
+// by routing through DBAccess, so batchserver.ComputeParities reads under
+// the same dbMu read lock as every other handler instead of touching
+// s.database directly.
+type dbBackend struct{ s *PlinkoPIRServer }
+
+func (b dbBackend) Len() uint64 { return b.s.dbSize }
+
+func (b dbBackend) Get(i uint64) ([4]uint64, error) {
+	if i >= b.s.dbSize {
+		return [4]uint64{}, storage.ErrOutOfRange
+	}
+	return [4]uint64(b.s.DBAccess(i)), nil
+}
+
+func (b dbBackend) Stream(from, to uint64) storage.Iterator {
+	if to > b.s.dbSize {
+		to = b.s.dbSize
+	}
+	if from > to {
+		from = to
+	}
+	return &dbBackendIterator{s: b.s, cur: from, to: to}
+}
+
+func (b dbBackend) Close() error { return nil }
+
+type dbBackendIterator struct {
+	s       *PlinkoPIRServer
+	cur, to uint64
+}
+
+func (it *dbBackendIterator) Next() (uint64, [4]uint64, bool, error) {
+	if it.cur >= it.to {
+		return 0, [4]uint64{}, false, nil
+	}
+	index := it.cur
+	it.cur++
+	return index, [4]uint64(it.s.DBAccess(index)), true, nil
+}
+
+func (it *dbBackendIterator) Close() error { return nil }
+
+// metricsHandler exposes the process's Prometheus metrics, including the
+// per-handler latency histograms and the db_size/chunk_size/set_size
+// gauges recorded at load time.
+func (s *PlinkoPIRServer) metricsHandler() http.Handler {
+	return promhttp.Handler()
+}
+
 func (s *PlinkoPIRServer) healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -159,6 +446,7 @@ func (s *PlinkoPIRServer) plaintextQueryHandler(w http.ResponseWriter, r *http.R
 	startTime := time.Now()
 	entry := s.DBAccess(req.Index)
 	elapsed := time.Since(startTime)
+	plaintextQueryDuration.Observe(elapsed.Seconds())
 
 	resp := PlaintextQueryResponse{
 		Value:           entry.String(),
@@ -190,6 +478,7 @@ func (s *PlinkoPIRServer) plinkoQueryHandler(w http.ResponseWriter, r *http.Requ
 	startTime := time.Now()
 	r0, r1 := s.HandlePlinkoQuery(req.P, req.Offsets)
 	elapsed := time.Since(startTime)
+	observePlinkoQuery(len(req.P), elapsed.Seconds())
 
 	log.Printf("Plinko query completed in %v\n", elapsed)
 
@@ -203,11 +492,286 @@ func (s *PlinkoPIRServer) plinkoQueryHandler(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(resp)
 }
 
+// PlinkoBatchRequest bundles several PlinkoQueryRequests into one round
+// trip, so a client pipelining many queries pays HTTP overhead once.
+type PlinkoBatchRequest struct {
+	Queries []PlinkoQueryRequest `json:"queries"`
+}
+
+// PlinkoBatchResponse holds one PlinkoQueryResponse per request, in the
+// same order as PlinkoBatchRequest.Queries.
+type PlinkoBatchResponse struct {
+	Responses []PlinkoQueryResponse `json:"responses"`
+}
+
+// plinkoBatchHandler serves POST /plinko/batch. Each query in the batch is
+// dispatched to a worker pool sized by GOMAXPROCS and answered with
+// HandlePlinkoQueryParallel, which itself shards the block loop across
+// workers; this turns a burst of queries into a many-core operation
+// instead of serializing the CPU-bound XOR walk.
+func (s *PlinkoPIRServer) plinkoBatchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req PlinkoBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Queries) > *maxBatchQueries {
+		http.Error(w, fmt.Sprintf("Batch of %d queries exceeds limit of %d", len(req.Queries), *maxBatchQueries), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	for _, q := range req.Queries {
+		if uint64(len(q.Offsets)) != s.setSize {
+			http.Error(w, "Invalid number of offsets", http.StatusBadRequest)
+			return
+		}
+	}
+
+	responses := make([]PlinkoQueryResponse, len(req.Queries))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, q := range req.Queries {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, q PlinkoQueryRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			startTime := time.Now()
+			r0, r1 := s.HandlePlinkoQueryParallel(q.P, q.Offsets)
+			elapsed := time.Since(startTime)
+			observePlinkoQuery(len(q.P), elapsed.Seconds())
+
+			responses[i] = PlinkoQueryResponse{
+				R0:              r0.String(),
+				R1:              r1.String(),
+				ServerTimeNanos: uint64(elapsed.Nanoseconds()),
+			}
+		}(i, q)
+	}
+	wg.Wait()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PlinkoBatchResponse{Responses: responses})
+}
+
+// CSRBatchRequest is the wire format for POST /plinko/batch/csr: the
+// CSR-packed layout produced by Client.QueryBatch. It's a distinct
+// protocol from PlinkoBatchRequest above, which carries one independent
+// P/offsets partition per query rather than a single batch-wide
+// deduplicated index set.
+type CSRBatchRequest struct {
+	Indices []uint64 `json:"indices"`
+	Members []uint32 `json:"members"`
+	Offsets []uint32 `json:"offsets"`
+}
+
+// CSRBatchResponse holds one parity per sub-query, in CSRBatchRequest's
+// Offsets order, matching what Client.ReconstructBatch expects per
+// HintHandle.
+type CSRBatchResponse struct {
+	Parities        []string `json:"parities"`
+	ServerTimeNanos uint64   `json:"server_time_nanos"`
+}
+
+// csrBatchHandler serves POST /plinko/batch/csr. It answers via
+// batchserver.ComputeParities, which reads each of req.Indices at most
+// once no matter how many sub-queries share it, then XORs each
+// sub-query's Members slice into its own parity -- the dedup that
+// Client.QueryBatch's CSR packing is for.
+func (s *PlinkoPIRServer) csrBatchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CSRBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Offsets) == 0 || req.Offsets[0] != 0 {
+		http.Error(w, "Invalid offsets", http.StatusBadRequest)
+		return
+	}
+	if len(req.Offsets)-1 > *maxBatchQueries {
+		http.Error(w, fmt.Sprintf("Batch of %d queries exceeds limit of %d", len(req.Offsets)-1, *maxBatchQueries), http.StatusRequestEntityTooLarge)
+		return
+	}
+	for _, pos := range req.Members {
+		if int(pos) >= len(req.Indices) {
+			http.Error(w, "Member position out of range", http.StatusBadRequest)
+			return
+		}
+	}
+
+	startTime := time.Now()
+	parities, err := batchserver.ComputeParities(dbBackend{s: s}, batchserver.Request{
+		Indices: req.Indices,
+		Members: req.Members,
+		Offsets: req.Offsets,
+	})
+	if err != nil {
+		http.Error(w, "Index out of range", http.StatusBadRequest)
+		return
+	}
+	elapsed := time.Since(startTime)
+	observeCSRBatchQuery(len(req.Offsets)-1, elapsed.Seconds())
+
+	resp := CSRBatchResponse{
+		Parities:        make([]string, len(parities)),
+		ServerTimeNanos: uint64(elapsed.Nanoseconds()),
+	}
+	for i, p := range parities {
+		resp.Parities[i] = DBEntry(p).String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// VerifyParamsResponse wraps the encoded verify.VerifierParams an
+// -verify-enabled server publishes once at startup, so a client fetches
+// it exactly once (it carries one EntryCommitments point per database
+// entry) rather than having it repeated in every query response.
+type VerifyParamsResponse struct {
+	Params []byte `json:"params"`
+}
+
+// verifyParamsHandler serves GET /verify/params. It 404s if the server
+// wasn't started with -verify, since there's no commitment to publish.
+func (s *PlinkoPIRServer) verifyParamsHandler(w http.ResponseWriter, r *http.Request) {
+	if s.commitment == nil {
+		http.Error(w, "Verified queries are not enabled on this server", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(VerifyParamsResponse{Params: verify.EncodeParams(s.commitment.Params)})
+}
+
+// VerifiedQueryRequest asks the server to answer a verified-mode query
+// over Subset: the already-punctured index set a verified hint (see
+// Client.ReconstructVerified) was built from. Unlike PlinkoQueryRequest's
+// P/Offsets bitset partition, Subset is an explicit index list, since
+// verify.SumParity's field-additive accumulation has no XOR-style
+// complement trick to fold the other side of the partition into the same
+// pass.
+type VerifiedQueryRequest struct {
+	Subset []uint64 `json:"subset"`
+}
+
+// VerifiedQueryResponse carries a verified-mode query's field-additive
+// parity alongside the opening proof a client needs to check it against
+// the published VerifierParams before trusting it (see
+// Client.ReconstructVerified).
+type VerifiedQueryResponse struct {
+	Parity          verify.FieldParity `json:"parity"`
+	Proof           []byte             `json:"proof"`
+	ServerTimeNanos uint64             `json:"server_time_nanos"`
+}
+
+// plinkoVerifiedQueryHandler serves POST /plinko/verify. It 404s if the
+// server wasn't started with -verify.
+func (s *PlinkoPIRServer) plinkoVerifiedQueryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.commitment == nil {
+		http.Error(w, "Verified queries are not enabled on this server", http.StatusNotFound)
+		return
+	}
+
+	var req VerifiedQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	startTime := time.Now()
+	parity, err := verify.SumParity(dbBackend{s: s}, req.Subset)
+	if err != nil {
+		http.Error(w, "Index out of range", http.StatusBadRequest)
+		return
+	}
+	proof := s.commitment.Open(req.Subset, parity)
+	elapsed := time.Since(startTime)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(VerifiedQueryResponse{
+		Parity:          parity,
+		Proof:           verify.EncodeOpening(proof),
+		ServerTimeNanos: uint64(elapsed.Nanoseconds()),
+	})
+}
+
+// updateHandler applies a single-index mutation to the in-memory database
+// and, if an update stream producer is configured, publishes the delta so
+// subscribed clients can apply the same mutation via Client.UpdateHint.
+// It's admin-only: the caller must present -admin-secret in the
+// X-Admin-Secret header, since anyone who can reach this endpoint can XOR
+// arbitrary deltas into the live database.
+func (s *PlinkoPIRServer) updateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !adminAuthorized(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if req.Index >= uint64(len(s.database)/DBEntryLength) {
+		http.Error(w, "Index out of range", http.StatusBadRequest)
+		return
+	}
+
+	s.applyDeltaLocal(req.Index, req.Delta)
+
+	if s.updateStream != nil {
+		if err := s.updateStream.Publish(req.Index, req.Delta); err != nil {
+			http.Error(w, "Failed to publish update", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 func (s *PlinkoPIRServer) HandlePlinkoQuery(P []uint64, offsets []uint64) (DBEntry, DBEntry) {
-	// Convert P slice to map for O(1) lookup
-	pMap := make(map[uint64]bool, len(P))
+	// Membership test for P is on the hot path of every query, so it's a
+	// bitset.BitSet rather than a map[uint64]bool: Set/Test are array
+	// accesses with no hashing or per-entry allocation, and the backing
+	// []uint64 is reused across requests via pSetPool. Indices are
+	// bounds-checked against setSize before Set, since bitset auto-extends
+	// its backing slice to cover whatever index it's given and the client
+	// controls P -- unlike the old map, an out-of-range index here would
+	// otherwise size an allocation off attacker-supplied data.
+	pSet := s.acquirePSet()
+	defer s.releasePSet(pSet)
+
 	for _, idx := range P {
-		pMap[idx] = true
+		if idx >= s.setSize {
+			continue
+		}
+		pSet.Set(uint(idx))
 	}
 
 	var r0, r1 DBEntry
@@ -225,16 +789,17 @@ func (s *PlinkoPIRServer) HandlePlinkoQuery(P []uint64, offsets []uint64) (DBEnt
 		
 		offset := offsets[i]
 		if offset >= s.chunkSize {
-			// Invalid offset for this chunk size, treat as 0 or skip? 
+			// Invalid offset for this chunk size, treat as 0 or skip?
 			// Ideally shouldn't happen if client is well-behaved.
 			// We'll wrap or clamp to be safe, or just proceed (DBAccess handles OOB)
+			plinkoInvalidOffsetsTotal.Inc()
 			offset %= s.chunkSize
 		}
 
 		dbIndex := i*s.chunkSize + offset
 		entry := s.DBAccess(dbIndex)
 
-		if pMap[i] {
+		if pSet.Test(uint(i)) {
 			// If block i is in P, add to r0
 			for k := 0; k < DBEntryLength; k++ {
 				r0[k] ^= entry[k]
@@ -250,6 +815,84 @@ func (s *PlinkoPIRServer) HandlePlinkoQuery(P []uint64, offsets []uint64) (DBEnt
 	return r0, r1
 }
 
+// HandlePlinkoQueryParallel computes the same (r0, r1) as HandlePlinkoQuery
+// but shards [0, setSize) across GOMAXPROCS workers, each accumulating a
+// local (r0, r1) pair that's XOR-reduced once all shards finish. pSet is
+// only ever read concurrently (Test), never mutated, so sharing it across
+// workers is safe.
+func (s *PlinkoPIRServer) HandlePlinkoQueryParallel(P []uint64, offsets []uint64) (DBEntry, DBEntry) {
+	pSet := s.acquirePSet()
+	defer s.releasePSet(pSet)
+	for _, idx := range P {
+		if idx >= s.setSize {
+			continue
+		}
+		pSet.Set(uint(idx))
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if uint64(workers) > s.setSize {
+		workers = int(s.setSize)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	type partial struct{ r0, r1 DBEntry }
+	shards := make([]partial, workers)
+	shardSize := (s.setSize + uint64(workers) - 1) / uint64(workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start := uint64(w) * shardSize
+		end := start + shardSize
+		if end > s.setSize {
+			end = s.setSize
+		}
+		if start >= end {
+			continue
+		}
+
+		wg.Add(1)
+		go func(w int, start, end uint64) {
+			defer wg.Done()
+
+			var r0, r1 DBEntry
+			for i := start; i < end; i++ {
+				offset := offsets[i]
+				if offset >= s.chunkSize {
+					plinkoInvalidOffsetsTotal.Inc()
+					offset %= s.chunkSize
+				}
+
+				dbIndex := i*s.chunkSize + offset
+				entry := s.DBAccess(dbIndex)
+
+				if pSet.Test(uint(i)) {
+					for k := 0; k < DBEntryLength; k++ {
+						r0[k] ^= entry[k]
+					}
+				} else {
+					for k := 0; k < DBEntryLength; k++ {
+						r1[k] ^= entry[k]
+					}
+				}
+			}
+			shards[w] = partial{r0: r0, r1: r1}
+		}(w, start, end)
+	}
+	wg.Wait()
+
+	var r0, r1 DBEntry
+	for _, shard := range shards {
+		for k := 0; k < DBEntryLength; k++ {
+			r0[k] ^= shard.r0[k]
+			r1[k] ^= shard.r1[k]
+		}
+	}
+	return r0, r1
+}
+
 // String returns the decimal string representation of the 256-bit integer
 func (e DBEntry) String() string {
 	// Convert [4]uint64 (little-endian) to big.Int
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"plinko-pir-server/pkg/client"
+	"plinko-pir-server/pkg/storage"
+	"plinko-pir-server/pkg/verify"
+)
+
+// TestVerifiedQueryRoundTrip exercises the verified-mode query path
+// end-to-end: a real Client builds a primary hint against its own copy of
+// the database (as OfflineSetup always does), a real PlinkoPIRServer
+// commits that same database and answers POST /plinko/verify and GET
+// /verify/params over actual HTTP, and the client recovers the target's
+// value through Client.ReconstructVerified using only what the server
+// returned.
+func TestVerifiedQueryRoundTrip(t *testing.T) {
+	const n = 64
+	db := make([][4]uint64, n)
+	for i := range db {
+		db[i] = [4]uint64{uint64(i) * 31, uint64(i) + 1000, 0, 0}
+	}
+
+	flatDB := make([]uint64, n*DBEntryLength)
+	for i, entry := range db {
+		base := uint64(i) * DBEntryLength
+		for w := 0; w < DBEntryLength; w++ {
+			flatDB[base+uint64(w)] = entry[w]
+		}
+	}
+
+	server := &PlinkoPIRServer{
+		database:  flatDB,
+		dbSize:    n,
+		chunkSize: 8,
+		setSize:   8,
+	}
+	var err error
+	server.commitment, err = verify.Commit(dbBackend{s: server})
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/plinko/verify", server.plinkoVerifiedQueryHandler)
+	mux.HandleFunc("/verify/params", server.verifyParamsHandler)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	// Fetch the published VerifierParams over HTTP, exactly as a real
+	// client would before trusting any proof.
+	paramsResp, err := http.Get(ts.URL + "/verify/params")
+	if err != nil {
+		t.Fatalf("GET /verify/params: %v", err)
+	}
+	defer paramsResp.Body.Close()
+	var paramsWire VerifyParamsResponse
+	if err := json.NewDecoder(paramsResp.Body).Decode(&paramsWire); err != nil {
+		t.Fatalf("decoding /verify/params response: %v", err)
+	}
+	params, err := verify.DecodeParams(paramsWire.Params)
+	if err != nil {
+		t.Fatalf("DecodeParams: %v", err)
+	}
+
+	keyAlpha := make([]byte, 16)
+	keyBeta := make([]byte, 16)
+	c := client.NewClient(n, 8, keyAlpha, keyBeta)
+
+	iter := 0
+	getStream := func() (client.DBEntry, bool) {
+		if iter >= n {
+			return client.DBEntry{}, false
+		}
+		entry := client.DBEntry{Index: uint64(iter), Value: db[iter]}
+		iter++
+		return entry, true
+	}
+	c.OfflineSetup(getStream, 4, 8)
+
+	target := uint64(23)
+	punctured, hint, ok := c.Query(target)
+	if !ok {
+		t.Fatalf("Query found no hint covering target %d", target)
+	}
+
+	full := append(append([]uint64{}, punctured...), target)
+	sort.Slice(full, func(i, j int) bool { return full[i] < full[j] })
+	var fullSum [4]uint64
+	for _, idx := range full {
+		for w := 0; w < 4; w++ {
+			fullSum[w] ^= db[idx][w]
+		}
+	}
+	if fullSum != hint.Parity {
+		t.Fatalf("test setup error: reconstructed full set doesn't match hint.Parity")
+	}
+
+	backend := storage.NewMemoryBackend(db)
+	fullParity, err := verify.SumParity(backend, full)
+	if err != nil {
+		t.Fatalf("SumParity(full): %v", err)
+	}
+
+	reqBody, err := json.Marshal(VerifiedQueryRequest{Subset: punctured})
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+	resp, err := http.Post(ts.URL+"/plinko/verify", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		t.Fatalf("POST /plinko/verify: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("POST /plinko/verify: status %d", resp.StatusCode)
+	}
+
+	var queryResp VerifiedQueryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&queryResp); err != nil {
+		t.Fatalf("decoding /plinko/verify response: %v", err)
+	}
+	proof, err := verify.DecodeOpening(queryResp.Proof)
+	if err != nil {
+		t.Fatalf("DecodeOpening: %v", err)
+	}
+
+	value, ok := c.ReconstructVerified(punctured, queryResp.Parity, fullParity, params, proof)
+	if !ok {
+		t.Fatalf("ReconstructVerified rejected the server's proof")
+	}
+	if value != db[target] {
+		t.Errorf("ReconstructVerified: got %v, want %v", value, db[target])
+	}
+}
+
+// TestVerifiedQueryRoundTripRejectsTamperedParity confirms a server that
+// answers with a different parity than it proved fails verification
+// instead of ReconstructVerified silently handing back a wrong value.
+func TestVerifiedQueryRoundTripRejectsTamperedParity(t *testing.T) {
+	const n = 16
+	db := make([][4]uint64, n)
+	for i := range db {
+		db[i] = [4]uint64{uint64(i) * 5, 0, 0, 0}
+	}
+
+	flatDB := make([]uint64, n*DBEntryLength)
+	for i, entry := range db {
+		base := uint64(i) * DBEntryLength
+		for w := 0; w < DBEntryLength; w++ {
+			flatDB[base+uint64(w)] = entry[w]
+		}
+	}
+
+	server := &PlinkoPIRServer{database: flatDB, dbSize: n, chunkSize: 4, setSize: 4}
+	var err error
+	server.commitment, err = verify.Commit(dbBackend{s: server})
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/plinko/verify", server.plinkoVerifiedQueryHandler)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	subset := []uint64{1, 3, 5}
+	reqBody, _ := json.Marshal(VerifiedQueryRequest{Subset: subset})
+	resp, err := http.Post(ts.URL+"/plinko/verify", "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		t.Fatalf("POST /plinko/verify: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var queryResp VerifiedQueryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&queryResp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	proof, err := verify.DecodeOpening(queryResp.Proof)
+	if err != nil {
+		t.Fatalf("DecodeOpening: %v", err)
+	}
+
+	tampered := queryResp.Parity
+	tampered[0][31]++
+
+	c := &client.Client{}
+	if _, ok := c.ReconstructVerified(subset, tampered, verify.FieldParity{}, server.commitment.Params, proof); ok {
+		t.Errorf("ReconstructVerified should reject a tampered parity")
+	}
+}
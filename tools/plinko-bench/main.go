@@ -6,11 +6,16 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
 	"plinko-pir-server/pkg/client"
+	"plinko-pir-server/pkg/updatestream"
 )
 
 const (
@@ -21,12 +26,26 @@ const (
 func main() {
 	dbPath := flag.String("db", "", "Path to database.bin")
 	numHints := flag.Int("hints", 100000, "Number of hints (m)")
+	updateBrokers := flag.String("update-brokers", "", "Comma-separated Kafka broker list to subscribe for hint updates (empty disables it)")
+	updateTopic := flag.String("update-topic", "plinko-db-updates", "Kafka topic to consume database deltas from")
+	updateGroup := flag.String("update-group", "plinko-bench", "Kafka consumer group ID")
+	metricsAddr := flag.String("metrics-addr", "", "Address to serve /metrics on (e.g. :9091), empty disables it")
 	flag.Parse()
 
 	if *dbPath == "" {
 		log.Fatal("Please provide -db path")
 	}
 
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		go func() {
+			if err := http.ListenAndServe(*metricsAddr, mux); err != nil {
+				log.Printf("metrics server stopped: %v", err)
+			}
+		}()
+	}
+
 	// Open file
 	f, err := os.Open(*dbPath)
 	if err != nil {
@@ -96,4 +115,17 @@ func main() {
     fmt.Printf("Allocated Mem (Delta): %v MB\n", (m2.TotalAlloc - m1.TotalAlloc) / 1024 / 1024)
     fmt.Printf("Heap Alloc: %v MB\n", m2.HeapAlloc / 1024 / 1024)
     fmt.Printf("System Mem: %v MB\n", m2.Sys / 1024 / 1024)
+
+	if *updateBrokers != "" {
+		fmt.Println("Subscribing to update stream...")
+		consumer, err := c.SubscribeUpdates(updatestream.Config{
+			Brokers: strings.Split(*updateBrokers, ","),
+			Topic:   *updateTopic,
+			Group:   *updateGroup,
+		})
+		if err != nil {
+			log.Fatalf("Failed to subscribe to update stream: %v", err)
+		}
+		defer consumer.Close()
+	}
 }